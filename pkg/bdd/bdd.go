@@ -0,0 +1,327 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bdd implements reduced ordered binary decision diagrams (BDDs): a canonical and often succinct
+// representation of boolean functions, and thus of sets of bit-vectors.
+// Values of several bits (e.g., IP addresses or ports) are encoded by groups of consecutive variables,
+// the most significant bit first, so that ranges of values have small diagrams (see Range and Spans).
+package bdd
+
+import (
+	"log"
+	"math/big"
+	"sync"
+)
+
+// Node is a node of a Manager, representing a boolean function of the manager's variables.
+// Nodes are reduced and shared, so two nodes of the same manager are equal iff they represent the same function.
+type Node int32
+
+const (
+	False Node = 0
+	True  Node = 1
+)
+
+type node struct {
+	level     int32 // the index of the node's variable, or the number of variables for the terminals
+	low, high Node  // the functions for the variable being false and true, respectively
+}
+
+// Manager holds the nodes of the functions of a fixed number of variables.
+// Nodes are never freed, so a manager grows with the number of distinct functions it ever represented;
+// to release the nodes of functions that are no longer used, import the used functions into a new manager (see Import).
+// A Manager is safe for concurrent use.
+type Manager struct {
+	mu      sync.Mutex
+	numVars int
+	nodes   []node
+	unique  map[node]Node
+}
+
+// NewManager returns a manager of functions of numVars variables
+func NewManager(numVars int) *Manager {
+	terminal := node{level: int32(numVars)} //nolint:gosec // the number of variables is small
+	return &Manager{numVars: numVars, nodes: []node{terminal, terminal}, unique: map[node]Node{}}
+}
+
+// NumVars returns the number of variables of the manager's functions
+func (m *Manager) NumVars() int {
+	return m.numVars
+}
+
+// NumNodes returns the number of nodes that the manager holds, including the two terminals
+func (m *Manager) NumNodes() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.nodes)
+}
+
+func (m *Manager) level(a Node) int32 {
+	return m.nodes[a].level
+}
+
+// mk returns the node of the function "if the variable of the given level then high else low"
+func (m *Manager) mk(level int32, low, high Node) Node {
+	if low == high {
+		return low
+	}
+	n := node{level: level, low: low, high: high}
+	if res, ok := m.unique[n]; ok {
+		return res
+	}
+	res := Node(len(m.nodes)) //nolint:gosec // the number of nodes is bounded by memory
+	m.nodes = append(m.nodes, n)
+	m.unique[n] = res
+	return res
+}
+
+// cofactors returns the functions of a for the variable of the given level being false and true
+func (m *Manager) cofactors(a Node, level int32) (low, high Node) {
+	if n := m.nodes[a]; n.level == level {
+		return n.low, n.high
+	}
+	return a, a
+}
+
+type operator int
+
+const (
+	and operator = iota
+	or
+	diff
+)
+
+// terminal returns the result of the operator, if it is determined without traversing its operands
+func terminal(op operator, a, b Node) (Node, bool) {
+	switch op {
+	case and:
+		switch {
+		case a == False || b == False:
+			return False, true
+		case a == True || a == b:
+			return b, true
+		case b == True:
+			return a, true
+		}
+	case or:
+		switch {
+		case a == True || b == True:
+			return True, true
+		case a == False || a == b:
+			return b, true
+		case b == False:
+			return a, true
+		}
+	case diff:
+		switch {
+		case a == False || b == True || a == b:
+			return False, true
+		case b == False:
+			return a, true
+		}
+	}
+	return False, false
+}
+
+func (m *Manager) apply(op operator, a, b Node, memo map[[2]Node]Node) Node {
+	if res, ok := terminal(op, a, b); ok {
+		return res
+	}
+	key := [2]Node{a, b}
+	if res, ok := memo[key]; ok {
+		return res
+	}
+	level := min(m.level(a), m.level(b))
+	a0, a1 := m.cofactors(a, level)
+	b0, b1 := m.cofactors(b, level)
+	res := m.mk(level, m.apply(op, a0, b0, memo), m.apply(op, a1, b1, memo))
+	memo[key] = res
+	return res
+}
+
+func (m *Manager) applyLocked(op operator, a, b Node) Node {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.apply(op, a, b, map[[2]Node]Node{})
+}
+
+// And returns the conjunction of a and b (the intersection of their sets)
+func (m *Manager) And(a, b Node) Node {
+	return m.applyLocked(and, a, b)
+}
+
+// Or returns the disjunction of a and b (the union of their sets)
+func (m *Manager) Or(a, b Node) Node {
+	return m.applyLocked(or, a, b)
+}
+
+// Diff returns the function "a and not b" (the subtraction of b's set from a's set)
+func (m *Manager) Diff(a, b Node) Node {
+	return m.applyLocked(diff, a, b)
+}
+
+// Not returns the negation of a (the complement of its set)
+func (m *Manager) Not(a Node) Node {
+	return m.applyLocked(diff, True, a)
+}
+
+// Var returns the function that is true iff the i-th variable is true
+func (m *Manager) Var(i int) Node {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mk(int32(i), False, True) //nolint:gosec // variable indices are small
+}
+
+// Range returns the function that is true iff the value of the variables [first, first+width),
+// read as an unsigned number with the most significant bit first, is in [lo, hi]
+func (m *Manager) Range(first, width int, lo, hi uint64) Node {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// geq and leq are the comparisons of the suffixes of the value, built from the least significant bit
+	geq, leq := True, True
+	for i := width - 1; i >= 0; i-- {
+		level := int32(first + i) //nolint:gosec // variable indices are small
+		bit := width - 1 - i
+		if lo>>bit&1 == 1 {
+			geq = m.mk(level, False, geq)
+		} else {
+			geq = m.mk(level, geq, True)
+		}
+		if hi>>bit&1 == 1 {
+			leq = m.mk(level, True, leq)
+		} else {
+			leq = m.mk(level, leq, False)
+		}
+	}
+	return m.apply(and, geq, leq, map[[2]Node]Node{})
+}
+
+// Count returns the number of assignments to all the variables that satisfy a (the size of its set)
+func (m *Manager) Count(a Node) *big.Int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := m.count(a, map[Node]*big.Int{})
+	return res.Lsh(res, uint(m.level(a)))
+}
+
+// count returns the number of satisfying assignments to the variables from the level of a
+func (m *Manager) count(a Node, memo map[Node]*big.Int) *big.Int {
+	switch a {
+	case False:
+		return big.NewInt(0)
+	case True:
+		return big.NewInt(1)
+	}
+	if res, ok := memo[a]; ok {
+		return new(big.Int).Set(res)
+	}
+	n := m.nodes[a]
+	low := m.count(n.low, memo)
+	low.Lsh(low, uint(m.level(n.low)-n.level-1))
+	high := m.count(n.high, memo)
+	high.Lsh(high, uint(m.level(n.high)-n.level-1))
+	res := low.Add(low, high)
+	memo[a] = new(big.Int).Set(res)
+	return res
+}
+
+// Import returns the node of m that represents the same function as the node a of src.
+// src should be a manager of the same number of variables.
+func (m *Manager) Import(src *Manager, a Node) Node {
+	if src == m {
+		return a
+	}
+	if src.numVars != m.numVars {
+		log.Panicf("cannot import a function of %d variables into a manager of %d variables", src.numVars, m.numVars)
+	}
+	nodes := src.reachable(a)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.importNode(a, nodes, map[Node]Node{False: False, True: True})
+}
+
+// reachable returns the non-terminal nodes that are reachable from a, by their indices
+func (m *Manager) reachable(a Node) map[Node]node {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := map[Node]node{}
+	var visit func(a Node)
+	visit = func(a Node) {
+		if _, ok := res[a]; ok || a == False || a == True {
+			return
+		}
+		res[a] = m.nodes[a]
+		visit(m.nodes[a].low)
+		visit(m.nodes[a].high)
+	}
+	visit(a)
+	return res
+}
+
+// importNode returns the node of m of the imported node a, given the imported nodes by their indices
+func (m *Manager) importNode(a Node, nodes map[Node]node, memo map[Node]Node) Node {
+	if res, ok := memo[a]; ok {
+		return res
+	}
+	n := nodes[a]
+	res := m.mk(n.level, m.importNode(n.low, nodes, memo), m.importNode(n.high, nodes, memo))
+	memo[a] = res
+	return res
+}
+
+// Span is a range of values of a group of variables, with the function of the following variables
+// that holds for all the values in the range
+type Span struct {
+	Lo, Hi uint64
+	Node   Node
+}
+
+type spanKey struct {
+	node Node
+	bit  int
+}
+
+// Spans decomposes a by the values of the variables [first, first+width), where width is less than 64:
+// a holds for an assignment iff its value of these variables is in some span, and the span's function holds for the
+// following variables. The spans are disjoint, sorted by their values, and touching spans have different functions.
+// a should not depend on the variables before first.
+func (m *Manager) Spans(a Node, first, width int) []Span {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if int(m.level(a)) < first {
+		log.Panicf("the function depends on variable %d, before the first variable %d", m.level(a), first)
+	}
+	return m.spans(a, first, width, 0, map[spanKey][]Span{})
+}
+
+// spans returns the spans of a by the variables [first+bit, first+width), with values relative to these variables
+func (m *Manager) spans(a Node, first, width, bit int, memo map[spanKey][]Span) []Span {
+	if a == False {
+		return nil
+	}
+	rem := width - bit
+	if int(m.level(a)) >= first+width {
+		return []Span{{Lo: 0, Hi: 1<<rem - 1, Node: a}}
+	}
+	key := spanKey{node: a, bit: bit}
+	if res, ok := memo[key]; ok {
+		return res
+	}
+	low, high := m.cofactors(a, int32(first+bit)) //nolint:gosec // variable indices are small
+	res := append([]Span{}, m.spans(low, first, width, bit+1, memo)...)
+	half := uint64(1) << (rem - 1)
+	for _, s := range m.spans(high, first, width, bit+1, memo) {
+		s.Lo += half
+		s.Hi += half
+		if last := len(res) - 1; last >= 0 && res[last].Node == s.Node && res[last].Hi+1 == s.Lo {
+			res[last].Hi = s.Hi
+			continue
+		}
+		res = append(res, s)
+	}
+	memo[key] = res
+	return res
+}
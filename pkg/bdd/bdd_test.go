@@ -0,0 +1,77 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bdd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/bdd"
+)
+
+func TestOperations(t *testing.T) {
+	m := bdd.NewManager(3)
+	x, y, z := m.Var(0), m.Var(1), m.Var(2)
+
+	require.Equal(t, m.And(x, y), m.And(y, x))
+	require.Equal(t, m.Or(m.And(x, y), m.And(x, z)), m.And(x, m.Or(y, z)))
+	require.Equal(t, m.Not(m.And(x, y)), m.Or(m.Not(x), m.Not(y)))
+	require.Equal(t, m.Diff(x, y), m.And(x, m.Not(y)))
+	require.Equal(t, bdd.True, m.Or(x, m.Not(x)))
+	require.Equal(t, bdd.False, m.And(x, m.Not(x)))
+
+	require.Equal(t, int64(4), m.Count(x).Int64())
+	require.Equal(t, int64(7), m.Count(m.Or(x, m.Or(y, z))).Int64())
+	require.Equal(t, int64(8), m.Count(bdd.True).Int64())
+	require.Equal(t, int64(0), m.Count(bdd.False).Int64())
+}
+
+func TestRange(t *testing.T) {
+	m := bdd.NewManager(16)
+	r := m.Range(0, 8, 10, 200)
+	require.Equal(t, int64(191*256), m.Count(r).Int64())
+	require.Equal(t, m.Range(0, 8, 10, 200), m.Or(m.Range(0, 8, 10, 99), m.Range(0, 8, 100, 200)))
+	require.Equal(t, bdd.False, m.And(m.Range(0, 8, 0, 9), r))
+	require.Equal(t, bdd.True, m.Range(8, 8, 0, 255))
+
+	spans := m.Spans(m.Or(r, m.Range(0, 8, 250, 250)), 0, 8)
+	require.Equal(t, []bdd.Span{{Lo: 10, Hi: 200, Node: bdd.True}, {Lo: 250, Hi: 250, Node: bdd.True}}, spans)
+}
+
+func TestSpans(t *testing.T) {
+	m := bdd.NewManager(16)
+	// x in [0, 99] and y in [1, 2], or x in [50, 255] and y = 7
+	a := m.And(m.Range(0, 8, 0, 99), m.Range(8, 8, 1, 2))
+	b := m.And(m.Range(0, 8, 50, 255), m.Range(8, 8, 7, 7))
+	spans := m.Spans(m.Or(a, b), 0, 8)
+	require.Len(t, spans, 3)
+	require.Equal(t, []uint64{0, 49, 50, 99, 100, 255},
+		[]uint64{spans[0].Lo, spans[0].Hi, spans[1].Lo, spans[1].Hi, spans[2].Lo, spans[2].Hi})
+	require.Equal(t, []bdd.Span{{Lo: 1, Hi: 2, Node: bdd.True}}, m.Spans(spans[0].Node, 8, 8))
+	require.Equal(t, []bdd.Span{{Lo: 1, Hi: 2, Node: bdd.True}, {Lo: 7, Hi: 7, Node: bdd.True}}, m.Spans(spans[1].Node, 8, 8))
+	require.Equal(t, []bdd.Span{{Lo: 7, Hi: 7, Node: bdd.True}}, m.Spans(spans[2].Node, 8, 8))
+
+	// the parity of x is not a range, so it has a span for each value
+	require.Len(t, m.Spans(m.Var(7), 0, 8), 128)
+	require.Panics(t, func() { m.Spans(m.Var(0), 8, 8) })
+}
+
+func TestImport(t *testing.T) {
+	m := bdd.NewManager(16)
+	a := m.Or(m.And(m.Range(0, 8, 0, 99), m.Range(8, 8, 1, 2)), m.Var(3))
+	m.Not(m.Range(8, 8, 5, 200)) // a function that is not imported
+
+	fresh := bdd.NewManager(16)
+	imported := fresh.Import(m, a)
+	require.Less(t, fresh.NumNodes(), m.NumNodes())
+	require.Zero(t, m.Count(a).Cmp(fresh.Count(imported)))
+	require.Equal(t, imported, fresh.Or(fresh.And(fresh.Range(0, 8, 0, 99), fresh.Range(8, 8, 1, 2)), fresh.Var(3)))
+	require.Equal(t, bdd.True, fresh.Import(m, bdd.True))
+	require.Equal(t, a, m.Import(m, a))
+	require.Panics(t, func() { bdd.NewManager(8).Import(m, a) })
+}
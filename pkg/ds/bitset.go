@@ -0,0 +1,269 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"math/big"
+	"math/bits"
+	"slices"
+	"strings"
+)
+
+const (
+	wordSize = 64
+	hashBase = 31
+)
+
+// Enumeration is a one-to-one mapping between a finite universe of values and the indices [0, Size())
+type Enumeration[T any] struct {
+	size   int
+	encode func(T) (int, bool)
+	decode func(int) T
+}
+
+// NewEnumeration returns the enumeration of the given distinct values, where each value is mapped to its position
+func NewEnumeration[T comparable](values ...T) *Enumeration[T] {
+	indices := make(map[T]int, len(values))
+	for i, v := range values {
+		if _, ok := indices[v]; ok {
+			log.Panicf("repeated value %v in enumeration", v)
+		}
+		indices[v] = i
+	}
+	values = slices.Clone(values)
+	return &Enumeration[T]{
+		size:   len(values),
+		encode: func(v T) (int, bool) { i, ok := indices[v]; return i, ok },
+		decode: func(i int) T { return values[i] },
+	}
+}
+
+// NewEnumerationFunc returns the enumeration of a universe of the given size, using the given mapping.
+// encode should return false for values outside the universe, and decode is called only with indices in [0, size).
+func NewEnumerationFunc[T any](size int, encode func(T) (int, bool), decode func(int) T) *Enumeration[T] {
+	return &Enumeration[T]{size: size, encode: encode, decode: decode}
+}
+
+// Size returns the number of values in the universe
+func (e *Enumeration[T]) Size() int {
+	return e.size
+}
+
+// Index returns the index of the value, or false if it is not in the universe
+func (e *Enumeration[T]) Index(v T) (int, bool) {
+	i, ok := e.encode(v)
+	return i, ok && i >= 0 && i < e.size
+}
+
+// Value returns the value of the given index
+func (e *Enumeration[T]) Value(i int) T {
+	if i < 0 || i >= e.size {
+		log.Panicf("index %d is out of the enumeration range [0-%d)", i, e.size)
+	}
+	return e.decode(i)
+}
+
+// BitSet is a set of values from the universe of an Enumeration, encoded as a bitset of their indices.
+// Sets of different enumerations should not be mixed.
+type BitSet[T any] struct {
+	enum  *Enumeration[T]
+	words []uint64
+}
+
+func emptyBitSet[T any](enum *Enumeration[T]) *BitSet[T] {
+	return &BitSet[T]{enum: enum, words: make([]uint64, (enum.size+wordSize-1)/wordSize)}
+}
+
+// NewBitSet returns the set of the given values. It panics if a value is not in the universe of the enumeration.
+func NewBitSet[T any](enum *Enumeration[T], values ...T) *BitSet[T] {
+	res := emptyBitSet(enum)
+	for _, v := range values {
+		i, ok := enum.Index(v)
+		if !ok {
+			log.Panicf("value %v is not in the enumeration", v)
+		}
+		res.set(i)
+	}
+	return res
+}
+
+// BitSetFromIndices returns the set of the values of the given indices
+func BitSetFromIndices[T any](enum *Enumeration[T], indices ...int) *BitSet[T] {
+	res := emptyBitSet(enum)
+	for _, i := range indices {
+		if i < 0 || i >= enum.size {
+			log.Panicf("index %d is out of the enumeration range [0-%d)", i, enum.size)
+		}
+		res.set(i)
+	}
+	return res
+}
+
+// FullBitSet returns the set of all the values of the enumeration
+func FullBitSet[T any](enum *Enumeration[T]) *BitSet[T] {
+	return emptyBitSet(enum).Complement()
+}
+
+func (b *BitSet[T]) set(i int) {
+	b.words[i/wordSize] |= 1 << (i % wordSize)
+}
+
+// Enumeration returns the enumeration of the set's universe
+func (b *BitSet[T]) Enumeration() *Enumeration[T] {
+	return b.enum
+}
+
+// Contains returns true if the value is in the set
+func (b *BitSet[T]) Contains(v T) bool {
+	i, ok := b.enum.Index(v)
+	return ok && b.ContainsIndex(i)
+}
+
+// ContainsIndex returns true if the value of the given index is in the set
+func (b *BitSet[T]) ContainsIndex(i int) bool {
+	return i >= 0 && i < b.enum.size && b.words[i/wordSize]&(1<<(i%wordSize)) != 0
+}
+
+// Indices returns the indices of the values in the set, in increasing order
+func (b *BitSet[T]) Indices() []int {
+	res := make([]int, 0, b.Size())
+	for w, word := range b.words {
+		for word != 0 {
+			res = append(res, w*wordSize+bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+	return res
+}
+
+// Elements returns the values in the set, ordered by their indices
+func (b *BitSet[T]) Elements() []T {
+	indices := b.Indices()
+	res := make([]T, len(indices))
+	for i, index := range indices {
+		res[i] = b.enum.decode(index)
+	}
+	return res
+}
+
+func (b *BitSet[T]) check(other *BitSet[T]) {
+	if b.enum != other.enum {
+		log.Panic("cannot combine bitsets of different enumerations")
+	}
+}
+
+func (b *BitSet[T]) combine(other *BitSet[T], op func(x, y uint64) uint64) *BitSet[T] {
+	b.check(other)
+	res := &BitSet[T]{enum: b.enum, words: make([]uint64, len(b.words))}
+	for i := range b.words {
+		res.words[i] = op(b.words[i], other.words[i])
+	}
+	return res
+}
+
+func (b *BitSet[T]) Equal(other *BitSet[T]) bool {
+	return b.enum == other.enum && slices.Equal(b.words, other.words)
+}
+
+func (b *BitSet[T]) Copy() *BitSet[T] {
+	return &BitSet[T]{enum: b.enum, words: slices.Clone(b.words)}
+}
+
+func (b *BitSet[T]) Hash() int {
+	res := 0
+	for _, word := range b.words {
+		res = res*hashBase + int(word) //nolint:gosec // overflow is fine for hashing
+	}
+	return res
+}
+
+func (b *BitSet[T]) IsEmpty() bool {
+	return !slices.ContainsFunc(b.words, func(word uint64) bool { return word != 0 })
+}
+
+// Size returns the number of values in the set
+func (b *BitSet[T]) Size() int {
+	res := 0
+	for _, word := range b.words {
+		res += bits.OnesCount64(word)
+	}
+	return res
+}
+
+// BigSize returns the number of values in the set
+func (b *BitSet[T]) BigSize() *big.Int {
+	return big.NewInt(int64(b.Size()))
+}
+
+// Compare returns -1 if b<other, 1 if b>other, 0 o.w.
+// Sets are compared as the numbers whose binary representations are their bitsets.
+func (b *BitSet[T]) Compare(other *BitSet[T]) int {
+	b.check(other)
+	for i := len(b.words) - 1; i >= 0; i-- {
+		if c := cmp.Compare(b.words[i], other.words[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (b *BitSet[T]) IsSubset(other *BitSet[T]) bool {
+	b.check(other)
+	for i := range b.words {
+		if b.words[i]&^other.words[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *BitSet[T]) Union(other *BitSet[T]) *BitSet[T] {
+	return b.combine(other, func(x, y uint64) uint64 { return x | y })
+}
+
+func (b *BitSet[T]) Intersect(other *BitSet[T]) *BitSet[T] {
+	return b.combine(other, func(x, y uint64) uint64 { return x & y })
+}
+
+func (b *BitSet[T]) Subtract(other *BitSet[T]) *BitSet[T] {
+	return b.combine(other, func(x, y uint64) uint64 { return x &^ y })
+}
+
+// Universe returns the set of all the values of the enumeration
+func (b *BitSet[T]) Universe() *BitSet[T] {
+	return FullBitSet(b.enum)
+}
+
+// Complement returns the values of the enumeration that are not in the set
+func (b *BitSet[T]) Complement() *BitSet[T] {
+	res := &BitSet[T]{enum: b.enum, words: make([]uint64, len(b.words))}
+	for i := range b.words {
+		res.words[i] = ^b.words[i]
+	}
+	// keep the bits beyond the universe unset, so that equal sets have equal words
+	if rest := b.enum.size % wordSize; rest != 0 {
+		res.words[len(res.words)-1] &= 1<<rest - 1
+	}
+	return res
+}
+
+func (b *BitSet[T]) IsAll() bool {
+	return b.Size() == b.enum.size
+}
+
+// String returns the values in the set, ordered by their indices, e.g., "{a, b}"
+func (b *BitSet[T]) String() string {
+	elements := b.Elements()
+	res := make([]string, len(elements))
+	for i, v := range elements {
+		res[i] = fmt.Sprint(v)
+	}
+	return "{" + strings.Join(res, ", ") + "}"
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/ds/settest"
+	"github.com/np-guard/models/pkg/interval"
+)
+
+var zones = ds.NewEnumeration("us-south-1", "us-south-2", "us-south-3", "eu-de-1", "eu-de-2")
+
+// tenants is an enumeration of more than a single word of bits
+var tenants = ds.NewEnumerationFunc(150,
+	func(id int64) (int, bool) { return int(id - 1000), id >= 1000 },
+	func(i int) int64 { return int64(i) + 1000 })
+
+func randomTenants(r *rand.Rand) *ds.BitSet[int64] {
+	var ids []int64
+	for range r.IntN(40) {
+		ids = append(ids, 1000+r.Int64N(150))
+	}
+	return ds.NewBitSet(tenants, ids...)
+}
+
+func TestBitSet(t *testing.T) {
+	south := ds.NewBitSet(zones, "us-south-1", "us-south-2", "us-south-3")
+	second := ds.NewBitSet(zones, "us-south-2", "eu-de-2")
+
+	require.Equal(t, "{us-south-2}", south.Intersect(second).String())
+	require.Equal(t, "{us-south-1, us-south-3}", south.Subtract(second).String())
+	require.Equal(t, 4, south.Union(second).Size())
+	require.Equal(t, []string{"eu-de-1"}, south.Union(second).Complement().Elements())
+	require.True(t, south.Contains("us-south-1"))
+	require.False(t, south.Contains("eu-de-1"))
+	require.False(t, south.Contains("mars-1"))
+	require.True(t, ds.FullBitSet(zones).IsAll())
+	require.True(t, ds.BitSetFromIndices(zones, 0, 1, 2).Equal(south))
+	require.Panics(t, func() { ds.NewBitSet(zones, "mars-1") })
+	require.Panics(t, func() { south.Union(ds.NewBitSet(ds.NewEnumeration("us-south-1"))) })
+
+	all := ds.FullBitSet(tenants)
+	require.Equal(t, 150, all.Size())
+	require.Equal(t, []int{0, 64, 128, 149}, ds.NewBitSet(tenants, 1149, 1128, 1064, 1000).Indices())
+	require.True(t, all.Subtract(ds.NewBitSet(tenants, 1149)).Complement().Equal(ds.NewBitSet(tenants, 1149)))
+}
+
+func TestBitSetProduct(t *testing.T) {
+	south := ds.NewBitSet(zones, "us-south-1", "us-south-2", "us-south-3")
+	p := ds.CartesianPairLeft(south, interval.New(1, 10).ToSet()).Union(
+		ds.CartesianPairLeft(ds.NewBitSet(zones, "eu-de-1"), interval.New(1, 10).ToSet()))
+	require.Equal(t, 1, p.NumPartitions())
+	require.Equal(t, 40, p.Size())
+	require.True(t, p.Left(ds.NewBitSet(zones)).Equal(ds.NewBitSet(zones, "us-south-1", "us-south-2", "us-south-3", "eu-de-1")))
+}
+
+func TestBitSetLaws(t *testing.T) {
+	settest.CheckUniversal(t, randomTenants, lawsIterations)
+	settest.Check(t, func(r *rand.Rand) ds.Product[*ds.BitSet[int64], *ds.BitSet[int64]] {
+		var res ds.Product[*ds.BitSet[int64], *ds.BitSet[int64]] = ds.NewProductLeft[*ds.BitSet[int64], *ds.BitSet[int64]]()
+		for range r.IntN(4) {
+			res = res.Union(ds.CartesianPairLeft(randomTenants(r), randomTenants(r)))
+		}
+		return res
+	}, lawsIterations)
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds
+
+// Compose returns the relational composition of p and q: the pairs (a, c) such that for some b,
+// (a, b) is in p and (b, c) is in q.
+func Compose[A Set[A], B Set[B], C Set[C]](p Product[A, B], q Product[B, C]) Product[A, C] {
+	var res Product[A, C] = NewProductLeft[A, C]()
+	for _, x := range p.Partitions() {
+		for _, y := range q.Partitions() {
+			if !x.Right.Intersect(y.Left).IsEmpty() {
+				res = res.Union(CartesianPairLeft(x.Left, y.Right))
+			}
+		}
+	}
+	return res
+}
+
+// ComposeTriples returns the composition of p and q joined on their middle dimension, where the third dimension
+// is common to both: the triples (a, c, t) such that for some b, (a, b, t) is in p and (b, c, t) is in q.
+func ComposeTriples[A Set[A], B Set[B], C Set[C], T Set[T]](p TripleSet[A, B, T], q TripleSet[B, C, T]) TripleSet[A, C, T] {
+	var res TripleSet[A, C, T] = NewLeftTripleSet[A, C, T]()
+	for _, x := range p.Partitions() {
+		for _, y := range q.Partitions() {
+			if x.S2.Intersect(y.S1).IsEmpty() {
+				continue
+			}
+			if common := x.S3.Intersect(y.S3); !common.IsEmpty() {
+				res = res.Union(CartesianLeftTriple(x.S1, y.S2, common))
+			}
+		}
+	}
+	return res
+}
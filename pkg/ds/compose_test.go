@@ -0,0 +1,32 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/ds"
+)
+
+func TestCompose(t *testing.T) {
+	p := rectangle(1, 10, 20, 30).Union(rectangle(11, 15, 40, 50))
+	q := rectangle(25, 45, 100, 200)
+
+	require.True(t, ds.Compose(p, q).Equal(rectangle(1, 15, 100, 200)))
+	require.True(t, ds.Compose(q, p).IsEmpty())
+	require.True(t, ds.Compose(rectangle(11, 15, 40, 50), rectangle(1, 39, 1, 1)).IsEmpty())
+}
+
+func TestComposeTriples(t *testing.T) {
+	p := cubioidLeft(1, 10, 20, 30, 1, 5)
+	q := cubioidLeft(25, 45, 100, 200, 3, 8).Union(cubioidLeft(20, 20, 300, 300, 6, 9))
+
+	require.True(t, ds.ComposeTriples(p, q).Equal(cubioidLeft(1, 10, 100, 200, 3, 5)))
+	require.True(t, ds.ComposeTriples(q, p).IsEmpty())
+}
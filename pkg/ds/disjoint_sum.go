@@ -6,6 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 
 package ds
 
+import "math/big"
+
 // Disjoint is the union of two disjoint (tagged) sets L and R.
 type Disjoint[L Set[L], R Set[R]] struct {
 	left  L
@@ -55,6 +57,14 @@ func (c *Disjoint[L, R]) Hash() int {
 	return c.left.Hash() ^ c.right.Hash()
 }
 
+// Compare returns -1 if c<other, 1 if c>other, 0 o.w., comparing the left sets first, and then the right sets.
+func (c *Disjoint[L, R]) Compare(other *Disjoint[L, R]) int {
+	if res := Compare(c.left, other.left); res != 0 {
+		return res
+	}
+	return Compare(c.right, other.right)
+}
+
 // IsEmpty returns true if both left and right sets are empty.
 func (c *Disjoint[L, R]) IsEmpty() bool {
 	return c.left.IsEmpty() && c.right.IsEmpty()
@@ -65,6 +75,11 @@ func (c *Disjoint[L, R]) Size() int {
 	return c.left.Size() + c.right.Size()
 }
 
+// BigSize returns the exact sum of the sizes of the left and right sets.
+func (c *Disjoint[L, R]) BigSize() *big.Int {
+	return new(big.Int).Add(BigSize(c.left), BigSize(c.right))
+}
+
 // IsSubset returns true if both left and right sets are subsets of the other's left and right sets.
 func (c *Disjoint[L, R]) IsSubset(other *Disjoint[L, R]) bool {
 	return c.left.IsSubset(other.left) && c.right.IsSubset(other.right)
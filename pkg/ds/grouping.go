@@ -0,0 +1,53 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds
+
+// GroupByS1 groups the elements of S1 by their image in S2 x S3. It returns pairs (s1, p), such that the s1 sets
+// are disjoint, t maps every element of s1 exactly to p, and no two pairs have equal p.
+// The groups are the keys of the canonical ProductLeft[S1, Product[S2, S3]] holding t.
+func GroupByS1[S1 Set[S1], S2 Set[S2], S3 Set[S3]](t TripleSet[S1, S2, S3]) []Pair[S1, Product[S2, S3]] {
+	var res Product[S1, Product[S2, S3]] = NewProductLeft[S1, Product[S2, S3]]()
+	for _, p := range t.Partitions() {
+		var image Product[S2, S3] = CartesianPairLeft(p.S2, p.S3)
+		res = res.Union(CartesianPairLeft(p.S1, image))
+	}
+	return res.Partitions()
+}
+
+// GroupByS2 groups the elements of S2 by their preimage in S1 x S3. It is the same as GroupByS1 on t with S1 and S2 swapped.
+func GroupByS2[S1 Set[S1], S2 Set[S2], S3 Set[S3]](t TripleSet[S1, S2, S3]) []Pair[S2, Product[S1, S3]] {
+	return GroupByS1(MapTripleSet(t, Triple[S1, S2, S3].Swap12))
+}
+
+// Refine returns the common refinement of two partitions, given as slices of disjoint sets:
+// the non-empty intersections of a set from a with a set from b, and the parts of each set not covered by
+// the other partition. The result holds disjoint non-empty sets, and its union equals the union of both partitions.
+func Refine[S Set[S]](a, b []S) []S {
+	var res []S
+	for _, x := range a {
+		rest := x
+		for _, y := range b {
+			if common := x.Intersect(y); !common.IsEmpty() {
+				res = append(res, common)
+				rest = rest.Subtract(common)
+			}
+		}
+		if !rest.IsEmpty() {
+			res = append(res, rest)
+		}
+	}
+	for _, y := range b {
+		rest := y
+		for _, x := range a {
+			rest = rest.Subtract(x)
+		}
+		if !rest.IsEmpty() {
+			res = append(res, rest)
+		}
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds_test
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/interval"
+)
+
+func TestGroupByS1(t *testing.T) {
+	// 1-10 -> {(20-30, 1-5)}, 11-15 -> {(20-30, 1-5), (40-50, 1-5)}
+	c := cubioidLeft(1, 15, 20, 30, 1, 5).Union(cubioidLeft(11, 15, 40, 50, 1, 5))
+
+	groups := ds.GroupByS1(c)
+	require.Len(t, groups, 2)
+	slices.SortFunc(groups, func(a, b ds.Pair[*interval.CanonicalSet, ds.Product[*interval.CanonicalSet, *interval.CanonicalSet]]) int {
+		return cmp.Compare(a.Left.Min(), b.Left.Min())
+	})
+	require.True(t, groups[0].Left.Equal(interval.New(1, 10).ToSet()))
+	require.True(t, groups[0].Right.Equal(rectangle(20, 30, 1, 5)))
+	require.True(t, groups[1].Left.Equal(interval.New(11, 15).ToSet()))
+	require.True(t, groups[1].Right.Equal(rectangle(20, 30, 1, 5).Union(rectangle(40, 50, 1, 5))))
+
+	// 20-30 <- {(1-15, 1-5)}, 40-50 <- {(11-15, 1-5)}
+	inbound := ds.GroupByS2(c)
+	require.Len(t, inbound, 2)
+	for _, g := range inbound {
+		if g.Left.Equal(interval.New(20, 30).ToSet()) {
+			require.True(t, g.Right.Equal(rectangle(1, 15, 1, 5)))
+		} else {
+			require.True(t, g.Left.Equal(interval.New(40, 50).ToSet()))
+			require.True(t, g.Right.Equal(rectangle(11, 15, 1, 5)))
+		}
+	}
+}
+
+func TestRefine(t *testing.T) {
+	a := []*interval.CanonicalSet{interval.New(1, 10).ToSet(), interval.New(11, 20).ToSet()}
+	b := []*interval.CanonicalSet{interval.New(5, 15).ToSet(), interval.New(30, 40).ToSet()}
+	res := ds.Refine(a, b)
+	strings := make([]string, len(res))
+	for i, s := range res {
+		strings[i] = s.String()
+	}
+	require.ElementsMatch(t, []string{"1-4", "5-10", "11-15", "16-20", "30-40"}, strings)
+	require.Empty(t, ds.Refine[*interval.CanonicalSet](nil, nil))
+}
@@ -115,3 +115,21 @@ func (m *HashSet[V]) Size() int {
 	}
 	return res
 }
+
+// Hash returns the hash value of the set. It does not depend on the order of insertion.
+func (m *HashSet[V]) Hash() int {
+	res := len(m.m)
+	for h := range m.m {
+		res ^= h
+	}
+	return res
+}
+
+// Union returns a new set holding the elements of both m and other.
+func (m *HashSet[V]) Union(other *HashSet[V]) *HashSet[V] {
+	res := m.Copy()
+	for _, v := range other.Items() {
+		res.Insert(v)
+	}
+	return res
+}
@@ -11,6 +11,11 @@ SPDX-License-Identifier: Apache-2.0
 // product of the two sets.
 package ds
 
+import (
+	"cmp"
+	"math/big"
+)
+
 type Comparable[Self any] interface {
 	Equal(Self) bool
 	Copy() Self
@@ -27,9 +32,45 @@ type Sized interface {
 	// Size returns the actual, full size of the set.
 	// For Product, it returns the number of pairs of concrete elements that belong to the product, not the number of Partitions().
 	// In other words, for Product, p.Size() == sum(s1.Size() * s2.Size() for _, (s1, s2) := range p.Partitions())
+	// The size of huge sets (e.g., products of IP address sets) may overflow int; see BigSized.
 	Size() int
 }
 
+// BigSized is implemented by sets whose size may overflow int. Use BigSize to get the exact size of any set.
+type BigSized interface {
+	// BigSize returns the exact size of the set, which equals Size() if the latter does not overflow
+	BigSize() *big.Int
+}
+
+// BigSize returns the exact size of s if it implements BigSized, and its Size otherwise
+func BigSize(s Sized) *big.Int {
+	if b, ok := s.(BigSized); ok {
+		return b.BigSize()
+	}
+	return big.NewInt(int64(s.Size()))
+}
+
+// Ordered is implemented by sets with a total order. Use Compare to compare any sets.
+type Ordered[Self any] interface {
+	// Compare returns -1, 0 or 1 if the set is less than, equal to, or greater than the other set, respectively,
+	// according to a total order of the sets of the type. Equal sets are compared as 0.
+	Compare(Self) int
+}
+
+// Compare compares a and b by their order if they implement Ordered. Otherwise, equal sets are compared as 0, and
+// other sets are compared by their String, which is a total order if String is canonical.
+func Compare[S Set[S]](a, b S) int {
+	if o, ok := any(a).(Ordered[S]); ok {
+		return o.Compare(b)
+	}
+	if a.Equal(b) {
+		return 0
+	}
+	return cmp.Compare(a.String(), b.String())
+}
+
+// Set is a set of elements of some type. Sets may also implement Ordered and BigSized, which all the sets of this
+// module do.
 type Set[Self any] interface {
 	Hashable[Self]
 	Sized
@@ -40,14 +81,29 @@ type Set[Self any] interface {
 	String() string
 }
 
+// Universal is a Set with a known universe: the set of all the elements it may hold
+type Universal[Self any] interface {
+	Set[Self]
+
+	// Universe returns the set of all the elements of the set's type
+	Universe() Self
+
+	// Complement returns the elements of the universe that are not in the set
+	Complement() Self
+
+	// IsAll returns true if the set equals its universe
+	IsAll() bool
+}
+
 // Product is a cartesian product of sets S1 x S2
 type Product[S1 Set[S1], S2 Set[S2]] interface {
 	Set[Product[S1, S2]]
+	Ordered[Product[S1, S2]]
+	BigSized
 
 	// Partitions returns a slice of pairs such that, for (p Product):
 	// 	  p.Equal(Union(CartesianPairLeft(s1, s2) for _, (s1, s2) := range p.Partitions())
-	// (note that the order is arbitrary; we do not return HashSet because Pair is not Hashable)
-	// Partitions returns a slice of pairs in the product set  (note that the order is arbitrary)
+	// The pairs are sorted by ComparePairs (we do not return HashSet because Pair is not Hashable)
 	Partitions() []Pair[S1, S2]
 
 	// NumPartitions returns len(Partitions()). It is different from Size() which should return the number of concrete pairs of elements.
@@ -66,6 +122,9 @@ type Product[S1 Set[S1], S2 Set[S2]] interface {
 // TripleSet is a 3-product of sets S1 x S2 x S3
 type TripleSet[S1 Set[S1], S2 Set[S2], S3 Set[S3]] interface {
 	Set[TripleSet[S1, S2, S3]]
+	Ordered[TripleSet[S1, S2, S3]]
+	BigSized
+	// Partitions returns a slice of triples, whose cartesian products union to the set, sorted by CompareTriples
 	Partitions() []Triple[S1, S2, S3]
 	// TODO: add NumPartitions() to this interface?
 }
@@ -0,0 +1,195 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// LabeledSet is a set S, partitioned into regions where each region is tagged with a non-empty set of labels L.
+// It is typically used to track provenance: the labels of a region are the IDs of the rules that cover it.
+// The implementation is canonical: regions are disjoint, and no two regions have the same set of labels.
+type LabeledSet[S Set[S], L Hashable[L]] struct {
+	m *HashMap[S, *HashSet[L]]
+}
+
+// NewLabeledSet creates an empty LabeledSet.
+func NewLabeledSet[S Set[S], L Hashable[L]]() *LabeledSet[S, L] {
+	return &LabeledSet[S, L]{m: NewHashMap[S, *HashSet[L]]()}
+}
+
+// LabeledSingleton returns a new LabeledSet holding the set s tagged with the given labels.
+// If s is empty or no labels are given, the result is an empty LabeledSet.
+func LabeledSingleton[S Set[S], L Hashable[L]](s S, labels ...L) *LabeledSet[S, L] {
+	res := NewLabeledSet[S, L]()
+	if !s.IsEmpty() && len(labels) > 0 {
+		res.m.Insert(s, NewHashSet(labels...))
+	}
+	return res
+}
+
+// LabeledCartesianPair returns a new LabeledSet holding the cartesian product k x v, tagged with the given labels.
+func LabeledCartesianPair[K Set[K], V Set[V], L Hashable[L]](k K, v V, labels ...L) *LabeledSet[Product[K, V], L] {
+	return LabeledSingleton[Product[K, V]](CartesianPairLeft(k, v), labels...)
+}
+
+// LabeledCartesianTriple returns a new LabeledSet holding the cartesian product s1 x s2 x s3, tagged with the given labels.
+func LabeledCartesianTriple[S1 Set[S1], S2 Set[S2], S3 Set[S3], L Hashable[L]](s1 S1, s2 S2, s3 S3,
+	labels ...L) *LabeledSet[TripleSet[S1, S2, S3], L] {
+	return LabeledSingleton[TripleSet[S1, S2, S3]](CartesianLeftTriple(s1, s2, s3), labels...)
+}
+
+// Equal returns true if both objects hold the same regions with the same labels.
+func (c *LabeledSet[S, L]) Equal(other *LabeledSet[S, L]) bool {
+	return c.m.Equal(other.m)
+}
+
+// Copy returns a deep copy of the LabeledSet.
+func (c *LabeledSet[S, L]) Copy() *LabeledSet[S, L] {
+	return &LabeledSet[S, L]{m: c.m.Copy()}
+}
+
+// IsEmpty returns true if the LabeledSet is empty.
+func (c *LabeledSet[S, L]) IsEmpty() bool {
+	return c.m.IsEmpty()
+}
+
+// Partitions returns the regions of the LabeledSet, each paired with its set of labels, sorted by the regions.
+func (c *LabeledSet[S, L]) Partitions() []Pair[S, *HashSet[L]] {
+	res := c.m.Pairs()
+	slices.SortFunc(res, func(a, b Pair[S, *HashSet[L]]) int { return Compare(a.Left, b.Left) })
+	return res
+}
+
+// Region returns the underlying (unlabeled) set, given input of an empty set in S.
+func (c *LabeledSet[S, L]) Region(empty S) S {
+	res := empty.Copy()
+	for _, p := range c.m.Pairs() {
+		res = res.Union(p.Left)
+	}
+	return res
+}
+
+// Labels returns the labels of all regions that overlap s.
+func (c *LabeledSet[S, L]) Labels(s S) *HashSet[L] {
+	res := NewHashSet[L]()
+	for _, p := range c.m.Pairs() {
+		if !p.Left.Intersect(s).IsEmpty() {
+			res = res.Union(p.Right)
+		}
+	}
+	return res
+}
+
+// Union returns a new LabeledSet holding the regions of both c and other.
+// Where regions overlap, the labels of both are merged.
+func (c *LabeledSet[S, L]) Union(other *LabeledSet[S, L]) *LabeledSet[S, L] {
+	res := NewLabeledSet[S, L]()
+	for _, pair := range c.m.Pairs() {
+		leftover := pair.Left
+		for _, otherPair := range other.m.Pairs() {
+			common := pair.Left.Intersect(otherPair.Left)
+			if common.IsEmpty() {
+				continue
+			}
+			res.m.Insert(common, pair.Right.Union(otherPair.Right))
+			leftover = leftover.Subtract(common)
+		}
+		res.insert(leftover, pair.Right)
+	}
+	for _, otherPair := range other.m.Pairs() {
+		leftover := otherPair.Left
+		for _, pair := range c.m.Pairs() {
+			leftover = leftover.Subtract(pair.Left)
+		}
+		res.insert(leftover, otherPair.Right)
+	}
+	res.canonicalize()
+	return res
+}
+
+// Intersect returns a new LabeledSet holding the regions common to c and other, tagged with the labels of both.
+func (c *LabeledSet[S, L]) Intersect(other *LabeledSet[S, L]) *LabeledSet[S, L] {
+	res := NewLabeledSet[S, L]()
+	for _, pair := range c.m.Pairs() {
+		for _, otherPair := range other.m.Pairs() {
+			res.insert(pair.Left.Intersect(otherPair.Left), pair.Right.Union(otherPair.Right))
+		}
+	}
+	res.canonicalize()
+	return res
+}
+
+// Subtract returns a new LabeledSet holding the regions of c that are not covered by other, with their original labels.
+func (c *LabeledSet[S, L]) Subtract(other *LabeledSet[S, L]) *LabeledSet[S, L] {
+	res := NewLabeledSet[S, L]()
+	for _, pair := range c.m.Pairs() {
+		leftover := pair.Left
+		for _, otherPair := range other.m.Pairs() {
+			leftover = leftover.Subtract(otherPair.Left)
+		}
+		res.insert(leftover, pair.Right)
+	}
+	res.canonicalize()
+	return res
+}
+
+// Restrict returns a new LabeledSet holding the regions of c intersected with the (unlabeled) set s, with their original labels.
+func (c *LabeledSet[S, L]) Restrict(s S) *LabeledSet[S, L] {
+	res := NewLabeledSet[S, L]()
+	for _, pair := range c.m.Pairs() {
+		res.insert(pair.Left.Intersect(s), pair.Right)
+	}
+	res.canonicalize()
+	return res
+}
+
+// insert adds a region with its labels, assuming it does not overlap existing regions. Empty regions are ignored.
+func (c *LabeledSet[S, L]) insert(region S, labels *HashSet[L]) {
+	if !region.IsEmpty() {
+		c.m.Insert(region, labels)
+	}
+}
+
+// canonicalize unions regions with equivalent labels to a single region
+func (c *LabeledSet[S, L]) canonicalize() {
+	newM := NewHashMap[S, *HashSet[L]]()
+	for _, p := range InverseMap(c.m).MultiPairs() {
+		regions := p.Right.Items()
+		if len(regions) == 0 {
+			continue
+		}
+		region := regions[0]
+		for _, r := range regions[1:] {
+			region = region.Union(r)
+		}
+		newM.Insert(region, p.Left)
+	}
+	c.m = newM
+}
+
+func labelsString[L Hashable[L]](labels *HashSet[L]) string {
+	items := labels.Items()
+	labelsStrings := make([]string, len(items))
+	for i, l := range items {
+		labelsStrings[i] = fmt.Sprint(l)
+	}
+	sort.Strings(labelsStrings)
+	return "[" + strings.Join(labelsStrings, ", ") + "]"
+}
+
+func (c *LabeledSet[S, L]) String() string {
+	partitions := c.m.Pairs()
+	partitionsStrings := make([]string, len(partitions))
+	for i, pair := range partitions {
+		partitionsStrings[i] = pair.Left.String() + ": " + labelsString(pair.Right)
+	}
+	return setString(partitionsStrings)
+}
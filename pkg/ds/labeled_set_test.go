@@ -0,0 +1,70 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/interval"
+)
+
+type labeledRectangles = ds.LabeledSet[ds.Product[*interval.CanonicalSet, *interval.CanonicalSet], Int]
+
+func labeledRectangle(s1, e1, s2, e2 int64, labels ...int) *labeledRectangles {
+	ints := make([]Int, len(labels))
+	for i, l := range labels {
+		ints[i] = Int{l}
+	}
+	return ds.LabeledCartesianPair(interval.New(s1, e1).ToSet(), interval.New(s2, e2).ToSet(), ints...)
+}
+
+func labelsOf(s *labeledRectangles, p ds.Product[*interval.CanonicalSet, *interval.CanonicalSet]) []int {
+	var res []int
+	for _, l := range s.Labels(p).Items() {
+		res = append(res, l.int)
+	}
+	return res
+}
+
+func TestLabeledSetUnion(t *testing.T) {
+	a := labeledRectangle(1, 10, 1, 10, 1)
+	b := labeledRectangle(5, 15, 1, 10, 2)
+	u := a.Union(b)
+	fmt.Println(u) // {(1-4 x 1-10): [{1}] | (11-15 x 1-10): [{2}] | (5-10 x 1-10): [{1}, {2}]}
+	require.Len(t, u.Partitions(), 3)
+	require.ElementsMatch(t, []int{1}, labelsOf(u, rectangle(1, 1, 1, 1)))
+	require.ElementsMatch(t, []int{1, 2}, labelsOf(u, rectangle(7, 7, 3, 3)))
+	require.ElementsMatch(t, []int{2}, labelsOf(u, rectangle(12, 12, 3, 3)))
+	require.Empty(t, labelsOf(u, rectangle(20, 20, 3, 3)))
+	require.True(t, u.Equal(b.Union(a)))
+	require.True(t, u.Region(ds.NewProductLeft[*interval.CanonicalSet, *interval.CanonicalSet]()).Equal(rectangle(1, 15, 1, 10)))
+
+	// regions with the same labels are merged
+	c := labeledRectangle(1, 10, 1, 10, 1).Union(labeledRectangle(11, 20, 1, 10, 1))
+	require.True(t, c.Equal(labeledRectangle(1, 20, 1, 10, 1)))
+}
+
+func TestLabeledSetIntersectSubtract(t *testing.T) {
+	a := labeledRectangle(1, 10, 1, 10, 1)
+	b := labeledRectangle(5, 15, 1, 10, 2)
+
+	i := a.Intersect(b)
+	require.True(t, i.Equal(labeledRectangle(5, 10, 1, 10, 1, 2)))
+
+	s := a.Union(b).Subtract(labeledRectangle(1, 6, 1, 10, 3))
+	require.True(t, s.Equal(labeledRectangle(7, 10, 1, 10, 1, 2).Union(labeledRectangle(11, 15, 1, 10, 2))))
+
+	r := a.Union(b).Restrict(rectangle(8, 12, 5, 5))
+	require.True(t, r.Equal(labeledRectangle(8, 10, 5, 5, 1, 2).Union(labeledRectangle(11, 12, 5, 5, 2))))
+
+	require.True(t, a.Subtract(a).IsEmpty())
+	require.True(t, labeledRectangle(1, 10, 1, 10).IsEmpty())
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/ds/settest"
+	"github.com/np-guard/models/pkg/interval"
+)
+
+const lawsIterations = 200
+
+type intervalTriples = ds.TripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *interval.CanonicalSet]
+
+// randomRange returns a short range in [0, 20], so that random sets overlap
+func randomRange(r *rand.Rand) (start, end int64) {
+	start = r.Int64N(16)
+	return start, start + r.Int64N(5)
+}
+
+func randomRectangles(r *rand.Rand) ds.Product[*interval.CanonicalSet, *interval.CanonicalSet] {
+	var res ds.Product[*interval.CanonicalSet, *interval.CanonicalSet] = ds.NewProductLeft[*interval.CanonicalSet, *interval.CanonicalSet]()
+	for range r.IntN(4) {
+		s1, e1 := randomRange(r)
+		s2, e2 := randomRange(r)
+		res = res.Union(rectangle(s1, e1, s2, e2))
+	}
+	return res
+}
+
+func randomCubioids(empty intervalTriples, cubioid func(s1, e1, s2, e2, s3, e3 int64) intervalTriples) settest.Generator[intervalTriples] {
+	return func(r *rand.Rand) intervalTriples {
+		res := empty
+		for range r.IntN(4) {
+			s1, e1 := randomRange(r)
+			s2, e2 := randomRange(r)
+			s3, e3 := randomRange(r)
+			res = res.Union(cubioid(s1, e1, s2, e2, s3, e3))
+		}
+		return res
+	}
+}
+
+func TestSetLaws(t *testing.T) {
+	settest.Check(t, randomRectangles, lawsIterations)
+	settest.Check(t, randomCubioids(ds.NewLeftTripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *interval.CanonicalSet](),
+		cubioidLeft), lawsIterations)
+	settest.Check(t, randomCubioids(ds.NewRightTripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *interval.CanonicalSet](),
+		cubioidRight), lawsIterations)
+	settest.Check(t, randomCubioids(ds.NewOuterTripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *interval.CanonicalSet](),
+		cubioidOuter), lawsIterations)
+	settest.CheckUniversal(t, settest.RandomBits, lawsIterations)
+}
+
+func FuzzProductLaws(f *testing.F) {
+	settest.Fuzz(f, randomRectangles)
+}
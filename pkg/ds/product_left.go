@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package ds
 
 import (
+	"math/big"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -15,7 +17,8 @@ import (
 // The implementation represents the sets succinctly, merging keys with equivalent values to a single key,
 // so the mapping is injective (one-to-one).
 type ProductLeft[K Set[K], V Set[V]] struct {
-	m *HashMap[K, V]
+	m      *HashMap[K, V]
+	sorted sortedCache[Pair[K, V]]
 }
 
 // NewProductLeft with parameters [K, V] creates an empty Product[K, V] object,
@@ -37,7 +40,7 @@ func CartesianPairLeft[K Set[K], V Set[V]](k K, v V) *ProductLeft[K, V] {
 // Left returns the projection Product[K, V] on the set K.
 func (m *ProductLeft[K, V]) Left(empty K) K {
 	res := empty.Copy()
-	for _, p := range m.Partitions() {
+	for _, p := range m.m.Pairs() {
 		res = res.Union(p.Left)
 	}
 	return res
@@ -46,23 +49,31 @@ func (m *ProductLeft[K, V]) Left(empty K) K {
 // Right returns the projection Product[K, V] on the set V.
 func (m *ProductLeft[K, V]) Right(empty V) V {
 	res := empty.Copy()
-	for _, p := range m.Partitions() {
+	for _, p := range m.m.Pairs() {
 		res = res.Union(p.Right)
 	}
 	return res
 }
 
+// ProductLeftFromDisjoint returns the union of the cartesian products of the given pairs, whose left sets should be
+// pairwise disjoint. Unlike unioning the pairs one by one, it takes linear time in the number of pairs.
+func ProductLeftFromDisjoint[K Set[K], V Set[V]](pairs ...Pair[K, V]) *ProductLeft[K, V] {
+	res := NewProductLeft[K, V]()
+	for _, pair := range pairs {
+		if !pair.Right.IsEmpty() {
+			res.m.Insert(pair.Left, pair.Right)
+		}
+	}
+	res.canonicalize()
+	return res
+}
+
 func asLeftProduct[K Set[K], V Set[V]](m Product[K, V]) *ProductLeft[K, V] {
 	p, ok := m.(*ProductLeft[K, V])
 	if ok {
 		return p
 	}
-	res := NewProductLeft[K, V]()
-	for _, pair := range m.Partitions() {
-		res.m.Insert(pair.Left, pair.Right)
-	}
-	res.canonicalize()
-	return res
+	return ProductLeftFromDisjoint(m.Partitions()...)
 }
 
 // Equal returns true if this and other are equivalent Product object.
@@ -79,12 +90,18 @@ func (m *ProductLeft[K, V]) Copy() Product[K, V] {
 func (m *ProductLeft[K, V]) Hash() int {
 	const rrr = 5
 	res := rrr
-	for _, p := range m.Partitions() {
+	for _, p := range m.m.Pairs() {
 		res ^= (p.Left.Hash() << 1) ^ p.Right.Hash()
 	}
 	return res
 }
 
+// Compare returns -1 if m<other, 1 if m>other, 0 o.w.
+// Products are ordered lexicographically by their sorted partitions.
+func (m *ProductLeft[K, V]) Compare(other Product[K, V]) int {
+	return slices.CompareFunc(m.sortedPartitions(), asLeftProduct(other).sortedPartitions(), ComparePairs)
+}
+
 // IsEmpty returns true if the Product object is empty.
 func (m *ProductLeft[K, V]) IsEmpty() bool {
 	return m.m.IsEmpty()
@@ -99,12 +116,21 @@ func (m *ProductLeft[K, V]) Size() int {
 	return res
 }
 
+// BigSize returns the exact number of unique pairs in the Product object
+func (m *ProductLeft[K, V]) BigSize() *big.Int {
+	res := new(big.Int)
+	for _, p := range m.m.Pairs() {
+		res.Add(res, new(big.Int).Mul(BigSize(p.Left), BigSize(p.Right)))
+	}
+	return res
+}
+
 // IsSubset returns true if m is a subset of other.
 func (m *ProductLeft[K, V]) IsSubset(other Product[K, V]) bool {
 	subsetCount := 0
-	for _, pair := range m.Partitions() {
+	for _, pair := range m.m.Pairs() {
 		LeftoverKey := pair.Left.Copy()
-		for _, otherPair := range other.Partitions() {
+		for _, otherPair := range asLeftProduct(other).m.Pairs() {
 			commonKey := otherPair.Left.Intersect(LeftoverKey)
 			if commonKey.IsEmpty() {
 				continue
@@ -138,9 +164,9 @@ func (m *ProductLeft[K, V]) Union(other Product[K, V]) Product[K, V] {
 		remainingFromSelf.Insert(k, k)
 	}
 	res := NewProductLeft[K, V]()
-	for _, otherPair := range other.Partitions() {
+	for _, otherPair := range asLeftProduct(other).m.Pairs() {
 		LeftoverKey := otherPair.Left // copy will happen upon insertion
-		for _, selfPair := range m.Partitions() {
+		for _, selfPair := range m.m.Pairs() {
 			commonElem := otherPair.Left.Intersect(selfPair.Left)
 			if commonElem.IsEmpty() {
 				continue
@@ -173,8 +199,8 @@ func (m *ProductLeft[K, V]) Intersect(other Product[K, V]) Product[K, V] {
 		return m.Copy()
 	}
 	res := NewProductLeft[K, V]()
-	for _, pair := range m.Partitions() {
-		for _, otherPair := range other.Partitions() {
+	for _, pair := range m.m.Pairs() {
+		for _, otherPair := range asLeftProduct(other).m.Pairs() {
 			commonELem := pair.Left.Intersect(otherPair.Left)
 			if commonELem.IsEmpty() {
 				continue
@@ -198,9 +224,9 @@ func (m *ProductLeft[K, V]) Subtract(other Product[K, V]) Product[K, V] {
 		return m.Copy()
 	}
 	res := NewProductLeft[K, V]()
-	for _, pair := range m.Partitions() {
+	for _, pair := range m.m.Pairs() {
 		LeftoverKey := pair.Left // copy will happen upon insertion
-		for _, otherPair := range other.Partitions() {
+		for _, otherPair := range asLeftProduct(other).m.Pairs() {
 			commonELem := pair.Left.Intersect(otherPair.Left)
 			if commonELem.IsEmpty() {
 				continue
@@ -239,6 +265,7 @@ func (m *ProductLeft[K, V]) canonicalize() {
 		newM.Insert(newKey, p.Left)
 	}
 	m.m = newM
+	m.sorted.p.Store(nil)
 }
 
 // NumPartitions returns the number of unique partitions in the Product object
@@ -246,9 +273,18 @@ func (m *ProductLeft[K, V]) NumPartitions() int {
 	return len(m.m.Pairs())
 }
 
-// Partitions returns a slice of all unique partitions in the Product object
+// Partitions returns a slice of all unique partitions in the Product object, sorted by their keys
 func (m *ProductLeft[K, V]) Partitions() []Pair[K, V] {
-	return m.m.Pairs()
+	return slices.Clone(m.sortedPartitions())
+}
+
+// sortedPartitions returns the cached sorted partitions, which must not be modified
+func (m *ProductLeft[K, V]) sortedPartitions() []Pair[K, V] {
+	return m.sorted.get(func() []Pair[K, V] {
+		res := m.m.Pairs()
+		slices.SortFunc(res, ComparePairs)
+		return res
+	})
 }
 
 // Swap returns a new Product object, built from the input Product object,
@@ -258,7 +294,7 @@ func (m *ProductLeft[K, V]) Swap() Product[V, K] {
 		return NewProductLeft[V, K]()
 	}
 	var res Product[V, K] = NewProductLeft[V, K]()
-	for _, pair := range m.Partitions() {
+	for _, pair := range m.m.Pairs() {
 		res = res.Union(CartesianPairLeft(pair.Right, pair.Left))
 	}
 	res.(*ProductLeft[V, K]).canonicalize()
@@ -266,7 +302,7 @@ func (m *ProductLeft[K, V]) Swap() Product[V, K] {
 }
 
 func (m *ProductLeft[K, V]) String() string {
-	partitions := m.Partitions()
+	partitions := m.m.Pairs()
 	partitionsStrings := make([]string, len(partitions))
 	for i, pair := range partitions {
 		partitionsStrings[i] = tupleString(pair.Left.String(), pair.Right.String())
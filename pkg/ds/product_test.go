@@ -448,3 +448,22 @@ func TestSubtract(t *testing.T) {
 	require.True(t, res2.IsEmpty())
 	fmt.Println(res1) // {(1-2 x 1-5)}
 }
+
+func TestProductPartitionsOrder(t *testing.T) {
+	p := rectangle(30, 40, 1, 5).Union(rectangle(1, 10, 6, 8)).Union(rectangle(15, 20, 1, 1))
+	partitions := p.Partitions()
+	require.Len(t, partitions, 3)
+	for i := range partitions[1:] {
+		require.Equal(t, -1, ds.ComparePairs(partitions[i], partitions[i+1]))
+	}
+	require.Equal(t, "1-10", partitions[0].Left.String())
+
+	// the sorted partitions are cached; modifying the returned slice does not affect them
+	partitions[0], partitions[2] = partitions[2], partitions[0]
+	require.Equal(t, "1-10", p.Partitions()[0].Left.String())
+
+	require.Equal(t, 0, p.Compare(p.Copy()))
+	require.Equal(t, -1, ds.NewProductLeft[*interval.CanonicalSet, *interval.CanonicalSet]().Compare(p))
+	require.Equal(t, -1, rectangle(1, 10, 6, 8).Compare(p))
+	require.Equal(t, 1, rectangle(1, 10, 6, 9).Compare(p))
+}
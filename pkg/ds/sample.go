@@ -0,0 +1,184 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds
+
+import (
+	"log"
+	"math/big"
+	"math/bits"
+	"math/rand/v2"
+	"sort"
+)
+
+// Sampler is a set of elements of type E that can be sampled uniformly
+type Sampler[E any] interface {
+	BigSize() *big.Int
+	// Sample returns an element of the set, chosen uniformly at random. It panics if the set is empty.
+	Sample(r *rand.Rand) E
+}
+
+// SampleN returns n elements of s, each chosen uniformly at random.
+// If distinct is true, the elements are distinct, and SampleN panics if s has less than n elements.
+func SampleN[E comparable](r *rand.Rand, s Sampler[E], n int, distinct bool) []E {
+	res := make([]E, 0, n)
+	if !distinct {
+		for range n {
+			res = append(res, s.Sample(r))
+		}
+		return res
+	}
+	if size := s.BigSize(); size.Cmp(big.NewInt(int64(n))) < 0 {
+		log.Panicf("cannot sample %d distinct elements from a set of %s elements", n, size)
+	}
+	seen := make(map[E]bool, n)
+	for len(res) < n {
+		if e := s.Sample(r); !seen[e] {
+			seen[e] = true
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// SampleIndex returns a random index i of weights, with probability weights[i] / sum(weights).
+// It panics if all the weights are zero, e.g., when sampling the partitions of an empty set.
+func SampleIndex(r *rand.Rand, weights ...*big.Int) int {
+	return NewIndexSampler(weights...).Sample(r)
+}
+
+// IndexSampler samples the indices of a list of weights, as SampleIndex does. It sums the weights once, so that
+// each sample takes logarithmic time in the number of weights.
+type IndexSampler struct {
+	cumulative []*big.Int // cumulative[i] is the sum of weights[0..i]
+}
+
+// NewIndexSampler returns a sampler of the indices of the given weights
+func NewIndexSampler(weights ...*big.Int) *IndexSampler {
+	res := &IndexSampler{cumulative: make([]*big.Int, len(weights))}
+	total := new(big.Int)
+	for i, w := range weights {
+		total.Add(total, w)
+		res.cumulative[i] = new(big.Int).Set(total)
+	}
+	return res
+}
+
+// BigSize returns the sum of the weights
+func (s *IndexSampler) BigSize() *big.Int {
+	if len(s.cumulative) == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(s.cumulative[len(s.cumulative)-1])
+}
+
+// Sample returns a random index i, with probability weights[i] / sum(weights). It panics if all the weights are zero.
+func (s *IndexSampler) Sample(r *rand.Rand) int {
+	total := s.BigSize()
+	if total.Sign() == 0 {
+		log.Panic("cannot sample from an empty set")
+	}
+	x := randBigInt(r, total)
+	// the first index whose cumulative weight exceeds x, which skips indices of zero weight
+	return sort.Search(len(s.cumulative), func(i int) bool { return x.Cmp(s.cumulative[i]) < 0 })
+}
+
+// randBigInt returns a uniform random number in [0, n), for a positive n
+func randBigInt(r *rand.Rand, n *big.Int) *big.Int {
+	if n.IsUint64() {
+		return new(big.Int).SetUint64(r.Uint64N(n.Uint64()))
+	}
+	// rejection sampling of numbers with the bit length of n, so that each attempt succeeds with probability above 1/2
+	words := make([]big.Word, (n.BitLen()+bits.UintSize-1)/bits.UintSize)
+	extra := uint(len(words)*bits.UintSize - n.BitLen())
+	res := new(big.Int)
+	for {
+		for i := range words {
+			words[i] = big.Word(uint(r.Uint64()))
+		}
+		words[len(words)-1] >>= extra
+		if res.SetBits(words).Cmp(n) < 0 {
+			return res
+		}
+	}
+}
+
+// sampledSet is a Set of type S whose elements of type E can be sampled
+type sampledSet[S, E any] interface {
+	Set[S]
+	Sampler[E]
+}
+
+// SampleProduct returns a pair of p, chosen uniformly at random: a partition is chosen by its size, and then each
+// of its sets is sampled. It panics if p is empty. To sample many pairs of p, use a ProductSampler.
+func SampleProduct[S1 sampledSet[S1, E1], S2 sampledSet[S2, E2], E1, E2 any](r *rand.Rand, p Product[S1, S2]) Pair[E1, E2] {
+	return NewProductSampler(p).Sample(r)
+}
+
+// ProductSampler samples the pairs of a Product uniformly, as SampleProduct does. It computes the partitions of the
+// product and their sizes once, so that sampling many pairs does not recompute them.
+type ProductSampler[S1 sampledSet[S1, E1], S2 sampledSet[S2, E2], E1, E2 any] struct {
+	partitions []Pair[S1, S2]
+	index      *IndexSampler
+}
+
+// NewProductSampler returns a sampler of the pairs of p
+func NewProductSampler[S1 sampledSet[S1, E1], S2 sampledSet[S2, E2], E1, E2 any](p Product[S1, S2]) *ProductSampler[S1, S2, E1, E2] {
+	partitions := p.Partitions()
+	weights := make([]*big.Int, len(partitions))
+	for i, partition := range partitions {
+		weights[i] = new(big.Int).Mul(BigSize(partition.Left), BigSize(partition.Right))
+	}
+	return &ProductSampler[S1, S2, E1, E2]{partitions: partitions, index: NewIndexSampler(weights...)}
+}
+
+// BigSize returns the number of pairs of the product
+func (s *ProductSampler[S1, S2, E1, E2]) BigSize() *big.Int {
+	return s.index.BigSize()
+}
+
+// Sample returns a pair of the product, chosen uniformly at random. It panics if the product is empty.
+func (s *ProductSampler[S1, S2, E1, E2]) Sample(r *rand.Rand) Pair[E1, E2] {
+	partition := s.partitions[s.index.Sample(r)]
+	return Pair[E1, E2]{Left: partition.Left.Sample(r), Right: partition.Right.Sample(r)}
+}
+
+// SampleTriple returns a triple of t, chosen uniformly at random: a partition is chosen by its size, and then each
+// of its sets is sampled. It panics if t is empty. To sample many triples of t, use a TripleSampler.
+func SampleTriple[S1 sampledSet[S1, E1], S2 sampledSet[S2, E2], S3 sampledSet[S3, E3], E1, E2, E3 any](r *rand.Rand,
+	t TripleSet[S1, S2, S3]) Triple[E1, E2, E3] {
+	return NewTripleSampler(t).Sample(r)
+}
+
+// TripleSampler samples the triples of a TripleSet uniformly, as SampleTriple does. It computes the partitions of the
+// set and their sizes once, so that sampling many triples does not recompute them.
+type TripleSampler[S1 sampledSet[S1, E1], S2 sampledSet[S2, E2], S3 sampledSet[S3, E3], E1, E2, E3 any] struct {
+	partitions []Triple[S1, S2, S3]
+	index      *IndexSampler
+}
+
+// NewTripleSampler returns a sampler of the triples of t
+func NewTripleSampler[S1 sampledSet[S1, E1], S2 sampledSet[S2, E2], S3 sampledSet[S3, E3], E1, E2, E3 any](
+	t TripleSet[S1, S2, S3]) *TripleSampler[S1, S2, S3, E1, E2, E3] {
+	partitions := t.Partitions()
+	weights := make([]*big.Int, len(partitions))
+	for i, partition := range partitions {
+		weights[i] = new(big.Int).Mul(BigSize(partition.S1), BigSize(partition.S2))
+		weights[i].Mul(weights[i], BigSize(partition.S3))
+	}
+	return &TripleSampler[S1, S2, S3, E1, E2, E3]{partitions: partitions, index: NewIndexSampler(weights...)}
+}
+
+// BigSize returns the number of triples of the set
+func (s *TripleSampler[S1, S2, S3, E1, E2, E3]) BigSize() *big.Int {
+	return s.index.BigSize()
+}
+
+// Sample returns a triple of the set, chosen uniformly at random. It panics if the set is empty.
+func (s *TripleSampler[S1, S2, S3, E1, E2, E3]) Sample(r *rand.Rand) Triple[E1, E2, E3] {
+	partition := s.partitions[s.index.Sample(r)]
+	return Triple[E1, E2, E3]{S1: partition.S1.Sample(r), S2: partition.S2.Sample(r), S3: partition.S3.Sample(r)}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds_test
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/interval"
+)
+
+const samples = 14000
+
+// requireAbout checks that count is within 10% of the expected count
+func requireAbout(t *testing.T, expected, count int) {
+	t.Helper()
+	require.InDelta(t, expected, count, float64(expected)/10)
+}
+
+func TestSampleIndex(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	huge := new(big.Int).Lsh(big.NewInt(1), 100)
+	counts := make([]int, 3)
+	for range samples {
+		counts[ds.SampleIndex(r, huge, big.NewInt(0), new(big.Int).Mul(huge, big.NewInt(3)))]++
+	}
+	require.Zero(t, counts[1])
+	requireAbout(t, samples/4, counts[0])
+	requireAbout(t, samples*3/4, counts[2])
+	require.Panics(t, func() { ds.SampleIndex(r, big.NewInt(0)) })
+	require.Zero(t, ds.NewIndexSampler(huge, huge).BigSize().Cmp(new(big.Int).Lsh(huge, 1)))
+}
+
+func TestSampleProduct(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	// 4 pairs in the first partition, and 10 pairs in the second
+	p := ds.CartesianPairLeft(interval.New(1, 2).ToSet(), interval.New(1, 2).ToSet()).Union(
+		ds.CartesianPairLeft(interval.New(10, 19).ToSet(), interval.New(1, 1).ToSet()))
+	counts := map[ds.Pair[int64, int64]]int{}
+	for range samples {
+		counts[ds.SampleProduct(r, p)]++
+	}
+	require.Len(t, counts, 14)
+	for pair, count := range counts {
+		require.Equal(t, 1, p.Intersect(ds.CartesianPairLeft(interval.New(pair.Left, pair.Left).ToSet(),
+			interval.New(pair.Right, pair.Right).ToSet())).Size())
+		requireAbout(t, samples/14, count)
+	}
+
+	triple := ds.SampleTriple(r, ds.CartesianLeftTriple(interval.New(1, 1).ToSet(), interval.New(2, 2).ToSet(),
+		interval.New(3, 3).ToSet()))
+	require.Equal(t, ds.Triple[int64, int64, int64]{S1: 1, S2: 2, S3: 3}, triple)
+	require.Panics(t, func() { ds.SampleProduct(r, ds.NewProductLeft[*interval.CanonicalSet, *interval.CanonicalSet]()) })
+}
+
+func TestSamplers(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	p := ds.CartesianPairLeft(interval.New(1, 2).ToSet(), interval.New(1, 2).ToSet()).Union(
+		ds.CartesianPairLeft(interval.New(10, 19).ToSet(), interval.New(1, 1).ToSet()))
+	products := ds.NewProductSampler(p)
+	require.Equal(t, int64(14), products.BigSize().Int64())
+	for _, pair := range ds.SampleN(r, products, 14, true) {
+		require.Equal(t, 1, p.Intersect(ds.CartesianPairLeft(interval.New(pair.Left, pair.Left).ToSet(),
+			interval.New(pair.Right, pair.Right).ToSet())).Size())
+	}
+
+	triples := ds.NewTripleSampler(ds.CartesianLeftTriple(interval.New(1, 2).ToSet(), interval.New(3, 3).ToSet(),
+		interval.New(4, 6).ToSet()))
+	require.Equal(t, int64(6), triples.BigSize().Int64())
+	require.Len(t, ds.SampleN(r, triples, 6, true), 6)
+	require.Panics(t, func() {
+		ds.NewTripleSampler(ds.NewLeftTripleSet[*interval.CanonicalSet, *interval.CanonicalSet,
+			*interval.CanonicalSet]()).Sample(r)
+	})
+}
+
+func TestSampleN(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	s := interval.New(1, 5).ToSet().Union(interval.New(10, 14).ToSet())
+	require.Len(t, ds.SampleN(r, s, 20, false), 20)
+	distinct := ds.SampleN(r, s, 10, true)
+	require.ElementsMatch(t, s.Elements(), distinct)
+	require.Panics(t, func() { ds.SampleN(r, s, 11, true) })
+}
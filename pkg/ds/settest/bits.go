@@ -0,0 +1,41 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package settest
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// Bits is a set of numbers in the universe [0, 7], implementing ds.Universal. It is a minimal fixture for tests of
+// generic code over sets.
+type Bits uint8
+
+func (b Bits) Equal(other Bits) bool     { return b == other }
+func (b Bits) Copy() Bits                { return b }
+func (b Bits) Hash() int                 { return int(b) }
+func (b Bits) Compare(other Bits) int    { return cmp.Compare(b, other) }
+func (b Bits) IsEmpty() bool             { return b == 0 }
+func (b Bits) Size() int                 { return bits.OnesCount8(uint8(b)) }
+func (b Bits) BigSize() *big.Int         { return big.NewInt(int64(b.Size())) }
+func (b Bits) IsSubset(other Bits) bool  { return b|other == other }
+func (b Bits) Union(other Bits) Bits     { return b | other }
+func (b Bits) Intersect(other Bits) Bits { return b & other }
+func (b Bits) Subtract(other Bits) Bits  { return b &^ other }
+func (b Bits) String() string            { return fmt.Sprintf("%08b", uint8(b)) }
+func (b Bits) Universe() Bits            { return ^Bits(0) }
+func (b Bits) Complement() Bits          { return ^b }
+func (b Bits) IsAll() bool               { return b == ^Bits(0) }
+
+// RandomBits is a Generator of Bits
+func RandomBits(r *rand.Rand) Bits {
+	return Bits(r.UintN(math.MaxUint8 + 1))
+}
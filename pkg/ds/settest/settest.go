@@ -0,0 +1,183 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package settest checks that implementations of ds.Set and ds.Universal satisfy the algebraic laws of sets.
+// The checks run on sets produced by a random generator, either from a fixed seed (see Check) or from
+// seeds chosen by the native fuzzing engine (see Fuzz).
+package settest
+
+import (
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/np-guard/models/pkg/ds"
+)
+
+// Generator returns a random set, using r as the only source of randomness.
+// For the laws to be meaningful, generated sets should often overlap, and sometimes be empty.
+type Generator[S any] func(r *rand.Rand) S
+
+// Variants returns a generator of the sets of gen, each either left as is or transformed by one of variants, chosen at
+// random, so that the laws are checked on operands that mix the variants, e.g., different representations of equal sets.
+func Variants[S any](gen Generator[S], variants ...func(S) S) Generator[S] {
+	return func(r *rand.Rand) S {
+		res := gen(r)
+		if i := r.IntN(len(variants) + 1); i < len(variants) {
+			res = variants[i](res)
+		}
+		return res
+	}
+}
+
+// seeds is the seed corpus of Fuzz and FuzzUniversal
+var seeds = [][2]uint64{{0, 0}, {1, 2}, {42, 7}, {1 << 63, 1<<64 - 1}}
+
+// Check checks the laws of ds.Set on n triples of sets generated by gen, from a fixed seed
+func Check[S ds.Set[S]](t *testing.T, gen Generator[S], n int) {
+	t.Helper()
+	check(t, gen, n, CheckSets[S])
+}
+
+// CheckUniversal checks the laws of ds.Universal on n triples of sets generated by gen, from a fixed seed
+func CheckUniversal[S ds.Universal[S]](t *testing.T, gen Generator[S], n int) {
+	t.Helper()
+	check(t, gen, n, CheckUniversalSets[S])
+}
+
+// Fuzz checks the laws of ds.Set on triples of sets generated by gen, from seeds chosen by the fuzzing engine
+func Fuzz[S ds.Set[S]](f *testing.F, gen Generator[S]) {
+	fuzz(f, gen, CheckSets[S])
+}
+
+// FuzzUniversal checks the laws of ds.Universal on triples of sets generated by gen, from seeds chosen by the fuzzing engine
+func FuzzUniversal[S ds.Universal[S]](f *testing.F, gen Generator[S]) {
+	fuzz(f, gen, CheckUniversalSets[S])
+}
+
+func check[S any](t *testing.T, gen Generator[S], n int, checkSets func(testing.TB, S, S, S)) {
+	t.Helper()
+	r := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // reproducible tests do not need cryptographic randomness
+	for i := 0; i < n && !t.Failed(); i++ {
+		checkSets(t, gen(r), gen(r), gen(r))
+	}
+}
+
+func fuzz[S any](f *testing.F, gen Generator[S], checkSets func(testing.TB, S, S, S)) {
+	for _, seed := range seeds {
+		f.Add(seed[0], seed[1])
+	}
+	f.Fuzz(func(t *testing.T, seed1, seed2 uint64) {
+		r := rand.New(rand.NewPCG(seed1, seed2)) //nolint:gosec // reproducible tests do not need cryptographic randomness
+		checkSets(t, gen(r), gen(r), gen(r))
+	})
+}
+
+// CheckSets checks the laws of ds.Set on the given sets:
+// commutativity, associativity, distributivity and idempotence of Union and Intersect, the relation of IsSubset
+// to Subtract, the consistency of Hash, Compare, Size and BigSize with Equal, and that no operation modifies its operands.
+func CheckSets[S ds.Set[S]](t testing.TB, a, b, c S) {
+	t.Helper()
+	strs := [3]string{a.String(), b.String(), c.String()}
+	l := laws[S]{t: t, a: a, b: b, c: c}
+
+	l.equal("a∪b = b∪a", a.Union(b), b.Union(a))
+	l.equal("a∩b = b∩a", a.Intersect(b), b.Intersect(a))
+	l.equal("(a∪b)∪c = a∪(b∪c)", a.Union(b).Union(c), a.Union(b.Union(c)))
+	l.equal("(a∩b)∩c = a∩(b∩c)", a.Intersect(b).Intersect(c), a.Intersect(b.Intersect(c)))
+	l.equal("a∩(b∪c) = (a∩b)∪(a∩c)", a.Intersect(b.Union(c)), a.Intersect(b).Union(a.Intersect(c)))
+	l.equal("a∪(b∩c) = (a∪b)∩(a∪c)", a.Union(b.Intersect(c)), a.Union(b).Intersect(a.Union(c)))
+	l.equal("a-(b∪c) = (a-b)∩(a-c)", a.Subtract(b.Union(c)), a.Subtract(b).Intersect(a.Subtract(c)))
+	l.equal("a-(b∩c) = (a-b)∪(a-c)", a.Subtract(b.Intersect(c)), a.Subtract(b).Union(a.Subtract(c)))
+	l.equal("a∪a = a", a.Union(a), a)
+	l.equal("a∩a = a", a.Intersect(a), a)
+	l.equal("a∪(a∩b) = a", a.Union(a.Intersect(b)), a)
+	l.equal("(a-b)∪(a∩b) = a", a.Subtract(b).Union(a.Intersect(b)), a)
+	l.holds("(a-b)∩b = ∅", a.Subtract(b).Intersect(b).IsEmpty())
+	l.holds("a-a = ∅", a.Subtract(a).IsEmpty())
+
+	l.holds("a⊆b ⇔ a-b = ∅", a.IsSubset(b) == a.Subtract(b).IsEmpty())
+	l.holds("a⊆b ⇔ a∪b = b", a.IsSubset(b) == a.Union(b).Equal(b))
+	l.holds("a∩b ⊆ a", a.Intersect(b).IsSubset(a))
+	l.holds("a ⊆ a∪b", a.IsSubset(a.Union(b)))
+	l.holds("a=b ⇔ a⊆b ∧ b⊆a", a.Equal(b) == (a.IsSubset(b) && b.IsSubset(a)))
+
+	// a is rebuilt through a different sequence of operations, to check that the representation is canonical
+	rebuilt := a.Union(b).Subtract(b.Subtract(a))
+	l.equal("(a∪b)-(b-a) = a", rebuilt, a)
+	l.holds("hash((a∪b)-(b-a)) = hash(a)", rebuilt.Hash() == a.Hash())
+	l.holds("copy(a) = a", a.Copy().Equal(a) && a.Copy().Hash() == a.Hash())
+	l.holds("a=b ⇒ hash(a) = hash(b)", !a.Equal(b) || a.Hash() == b.Hash())
+
+	compare := ds.Compare[S]
+	l.holds("compare(a, a) = 0", compare(a, a) == 0 && compare(a, rebuilt) == 0)
+	l.holds("compare(a, b) = 0 ⇔ a=b", (compare(a, b) == 0) == a.Equal(b))
+	l.holds("compare(a, b) = -compare(b, a)", compare(a, b) == -compare(b, a))
+	l.holds("compare(a, b) ≤ 0 ∧ compare(b, c) ≤ 0 ⇒ compare(a, c) ≤ 0",
+		compare(a, b) > 0 || compare(b, c) > 0 || compare(a, c) <= 0)
+
+	l.holds("a=∅ ⇔ |a| = 0", a.IsEmpty() == (a.Size() == 0))
+	l.holds("|a∪b| + |a∩b| = |a| + |b|", a.Union(b).Size()+a.Intersect(b).Size() == a.Size()+b.Size())
+	l.holds("|a-b| + |a∩b| = |a|", a.Subtract(b).Size()+a.Intersect(b).Size() == a.Size())
+	l.holds("|copy(a)| = |a|", a.Copy().Size() == a.Size())
+	l.holds("a=∅ ⇔ bigsize(a) = 0", a.IsEmpty() == (ds.BigSize(a).Sign() == 0))
+	l.holds("bigsize(a) = |a| unless |a| overflows", !ds.BigSize(a).IsInt64() || ds.BigSize(a).Int64() == int64(a.Size()))
+	l.holds("bigsize(a∪b) + bigsize(a∩b) = bigsize(a) + bigsize(b)",
+		sum(a.Union(b), a.Intersect(b)).Cmp(sum(a, b)) == 0)
+	l.holds("bigsize(a-b) + bigsize(a∩b) = bigsize(a)", sum(a.Subtract(b), a.Intersect(b)).Cmp(ds.BigSize(a)) == 0)
+
+	l.holds("operands are not modified", strs == [3]string{a.String(), b.String(), c.String()})
+}
+
+// CheckUniversalSets checks the laws of ds.Set on the given sets (see CheckSets), as well as
+// De Morgan's laws and the other laws relating sets to their universe and complement.
+func CheckUniversalSets[S ds.Universal[S]](t testing.TB, a, b, c S) {
+	t.Helper()
+	CheckSets(t, a, b, c)
+	l := laws[S]{t: t, a: a, b: b, c: c}
+	universe := a.Universe()
+
+	l.equal("universe(a) = universe(b)", universe, b.Universe())
+	l.holds("universe is all", universe.IsAll())
+	l.holds("a ⊆ universe", a.IsSubset(universe))
+	l.holds("a is all ⇔ a = universe", a.IsAll() == a.Equal(universe))
+	l.equal("a' = universe-a", a.Complement(), universe.Subtract(a))
+	l.equal("a'' = a", a.Complement().Complement(), a)
+	l.equal("a∪a' = universe", a.Union(a.Complement()), universe)
+	l.holds("a∩a' = ∅", a.Intersect(a.Complement()).IsEmpty())
+	l.equal("(a∪b)' = a'∩b'", a.Union(b).Complement(), a.Complement().Intersect(b.Complement()))
+	l.equal("(a∩b)' = a'∪b'", a.Intersect(b).Complement(), a.Complement().Union(b.Complement()))
+	l.equal("a-b = a∩b'", a.Subtract(b), a.Intersect(b.Complement()))
+	l.holds("a⊆b ⇔ b'⊆a'", a.IsSubset(b) == b.Complement().IsSubset(a.Complement()))
+	l.holds("bigsize(a) + bigsize(a') = bigsize(universe)", sum(a, a.Complement()).Cmp(ds.BigSize(universe)) == 0)
+	l.holds("fraction(a) + fraction(a') = 1", new(big.Rat).Add(ds.Fraction(a), ds.Fraction(a.Complement())).Cmp(big.NewRat(1, 1)) == 0)
+}
+
+// sum returns the sum of the exact sizes of x and y
+func sum[S ds.Set[S]](x, y S) *big.Int {
+	return new(big.Int).Add(ds.BigSize(x), ds.BigSize(y))
+}
+
+// laws reports violations of laws on a triple of sets
+type laws[S ds.Set[S]] struct {
+	t       testing.TB
+	a, b, c S
+}
+
+func (l laws[S]) holds(law string, ok bool) {
+	l.t.Helper()
+	if !ok {
+		l.t.Errorf("law %q does not hold for\na = %s\nb = %s\nc = %s", law, l.a.String(), l.b.String(), l.c.String())
+	}
+}
+
+func (l laws[S]) equal(law string, left, right S) {
+	l.t.Helper()
+	if !left.Equal(right) || !right.Equal(left) {
+		l.t.Errorf("law %q does not hold for\na = %s\nb = %s\nc = %s\nleft = %s\nright = %s",
+			law, l.a.String(), l.b.String(), l.c.String(), left.String(), right.String())
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package settest_test
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/ds/settest"
+)
+
+// BrokenBits is a set whose Subtract is wrong when the other set is empty
+type BrokenBits struct{ settest.Bits }
+
+func (b BrokenBits) Equal(other BrokenBits) bool           { return b.Bits == other.Bits }
+func (b BrokenBits) Copy() BrokenBits                      { return b }
+func (b BrokenBits) Compare(other BrokenBits) int          { return cmp.Compare(b.Bits, other.Bits) }
+func (b BrokenBits) IsSubset(other BrokenBits) bool        { return b.Bits.IsSubset(other.Bits) }
+func (b BrokenBits) Union(other BrokenBits) BrokenBits     { return BrokenBits{b.Bits | other.Bits} }
+func (b BrokenBits) Intersect(other BrokenBits) BrokenBits { return BrokenBits{b.Bits & other.Bits} }
+func (b BrokenBits) Subtract(other BrokenBits) BrokenBits {
+	if other.Bits == 0 {
+		return BrokenBits{}
+	}
+	return BrokenBits{b.Bits &^ other.Bits}
+}
+
+// PlainBits is a set that implements neither ds.Ordered nor ds.BigSized
+type PlainBits struct{ bits settest.Bits }
+
+func (b PlainBits) Equal(other PlainBits) bool          { return b.bits == other.bits }
+func (b PlainBits) Copy() PlainBits                     { return b }
+func (b PlainBits) Hash() int                           { return b.bits.Hash() }
+func (b PlainBits) IsEmpty() bool                       { return b.bits.IsEmpty() }
+func (b PlainBits) Size() int                           { return b.bits.Size() }
+func (b PlainBits) IsSubset(other PlainBits) bool       { return b.bits.IsSubset(other.bits) }
+func (b PlainBits) Union(other PlainBits) PlainBits     { return PlainBits{b.bits | other.bits} }
+func (b PlainBits) Intersect(other PlainBits) PlainBits { return PlainBits{b.bits & other.bits} }
+func (b PlainBits) Subtract(other PlainBits) PlainBits  { return PlainBits{b.bits &^ other.bits} }
+func (b PlainBits) String() string                      { return b.bits.String() }
+
+// recorder is a testing.TB that records errors instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheck(t *testing.T) {
+	settest.CheckUniversal(t, settest.RandomBits, 100)
+
+	rec := &recorder{TB: t}
+	settest.CheckUniversalSets(rec, settest.Bits(0b1100), settest.Bits(0b1010), settest.Bits(0b0110))
+	require.Empty(t, rec.errors)
+}
+
+func TestCheckPlain(t *testing.T) {
+	settest.Check(t, func(r *rand.Rand) PlainBits { return PlainBits{settest.RandomBits(r)} }, 100)
+	require.Equal(t, -1, ds.Compare(PlainBits{0b1}, PlainBits{0b10}))
+	require.Equal(t, int64(2), ds.BigSize(PlainBits{0b11}).Int64())
+}
+
+func TestVariants(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	gen := settest.Variants(func(*rand.Rand) settest.Bits { return 0b1 }, func(b settest.Bits) settest.Bits { return b | 0b10 })
+	counts := map[settest.Bits]int{}
+	for range 100 {
+		counts[gen(r)]++
+	}
+	require.Len(t, counts, 2)
+	require.Positive(t, counts[0b1])
+	require.Positive(t, counts[0b11])
+}
+
+func TestCheckBroken(t *testing.T) {
+	rec := &recorder{TB: t}
+	settest.CheckSets(rec, BrokenBits{0b11}, BrokenBits{0}, BrokenBits{0b1})
+	require.NotEmpty(t, rec.errors)
+	require.Contains(t, rec.errors[0], `law "a-(b∪c) = (a-b)∩(a-c)" does not hold`)
+}
+
+func FuzzCheck(f *testing.F) {
+	settest.FuzzUniversal(f, settest.RandomBits)
+}
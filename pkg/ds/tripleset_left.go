@@ -6,9 +6,15 @@ SPDX-License-Identifier: Apache-2.0
 
 package ds
 
+import (
+	"math/big"
+	"slices"
+)
+
 // LeftTripleSet is a left-associative 3-product of sets (S1 x S2) x S3
 type LeftTripleSet[S1 Set[S1], S2 Set[S2], S3 Set[S3]] struct {
-	m Product[Product[S1, S2], S3]
+	m      Product[Product[S1, S2], S3]
+	sorted sortedCache[Triple[S1, S2, S3]]
 }
 
 func NewLeftTripleSet[S1 Set[S1], S2 Set[S2], S3 Set[S3]]() *LeftTripleSet[S1, S2, S3] {
@@ -21,6 +27,12 @@ func CartesianLeftTriple[S1 Set[S1], S2 Set[S2], S3 Set[S3]](s1 S1, s2 S2, s3 S3
 	return &LeftTripleSet[S1, S2, S3]{m: r}
 }
 
+// LeftTripleSetFromDisjoint returns the union of the cartesian products p.Left x p.Right of the given pairs,
+// whose left products should be pairwise disjoint (see ProductLeftFromDisjoint)
+func LeftTripleSetFromDisjoint[S1 Set[S1], S2 Set[S2], S3 Set[S3]](pairs ...Pair[Product[S1, S2], S3]) *LeftTripleSet[S1, S2, S3] {
+	return &LeftTripleSet[S1, S2, S3]{m: ProductLeftFromDisjoint(pairs...)}
+}
+
 func (c *LeftTripleSet[S1, S2, S3]) Equal(other TripleSet[S1, S2, S3]) bool {
 	return c.m.Equal(AsLeftTripleSet(other).m)
 }
@@ -29,10 +41,16 @@ func (c *LeftTripleSet[S1, S2, S3]) Copy() TripleSet[S1, S2, S3] {
 	return &LeftTripleSet[S1, S2, S3]{m: c.m.Copy()}
 }
 
+// Compare returns -1 if c<other, 1 if c>other, 0 o.w.
+// Triple sets are ordered lexicographically by their sorted partitions.
+func (c *LeftTripleSet[S1, S2, S3]) Compare(other TripleSet[S1, S2, S3]) int {
+	return slices.CompareFunc(c.sortedPartitions(), AsLeftTripleSet(other).sortedPartitions(), CompareTriples[S1, S2, S3])
+}
+
 // S1 returns the projection TripleSet[S1, S2, S3] on the set S1.
 func (c *LeftTripleSet[S1, S2, S3]) S1(empty S1) S1 {
 	res := empty.Copy()
-	for _, p := range c.Partitions() {
+	for _, p := range partitionsMap(c, Triple[S1, S2, S3].ID) {
 		res = res.Union(p.S1)
 	}
 	return res
@@ -41,7 +59,7 @@ func (c *LeftTripleSet[S1, S2, S3]) S1(empty S1) S1 {
 // S2 returns the projection TripleSet[S1, S2, S3] on the set S2.
 func (c *LeftTripleSet[S1, S2, S3]) S2(empty S2) S2 {
 	res := empty.Copy()
-	for _, p := range c.Partitions() {
+	for _, p := range partitionsMap(c, Triple[S1, S2, S3].ID) {
 		res = res.Union(p.S2)
 	}
 	return res
@@ -50,7 +68,7 @@ func (c *LeftTripleSet[S1, S2, S3]) S2(empty S2) S2 {
 // S3 returns the projection TripleSet[S1, S2, S3] on the set S3.
 func (c *LeftTripleSet[S1, S2, S3]) S3(empty S3) S3 {
 	res := empty.Copy()
-	for _, p := range c.Partitions() {
+	for _, p := range partitionsMap(c, Triple[S1, S2, S3].ID) {
 		res = res.Union(p.S3)
 	}
 	return res
@@ -68,6 +86,11 @@ func (c *LeftTripleSet[S1, S2, S3]) Size() int {
 	return c.m.Size()
 }
 
+// BigSize returns the exact number of triples in the set
+func (c *LeftTripleSet[S1, S2, S3]) BigSize() *big.Int {
+	return c.m.BigSize()
+}
+
 func AsLeftTripleSet[S1 Set[S1], S2 Set[S2], S3 Set[S3]](other TripleSet[S1, S2, S3]) *LeftTripleSet[S1, S2, S3] {
 	r, ok := other.(*LeftTripleSet[S1, S2, S3])
 	if ok {
@@ -117,7 +140,18 @@ func partitionsMap[S1 Set[S1], S2 Set[S2], S3 Set[S3], Q any](c *LeftTripleSet[S
 }
 
 func (c *LeftTripleSet[S1, S2, S3]) Partitions() []Triple[S1, S2, S3] {
-	return partitionsMap(c, Triple[S1, S2, S3].ID)
+	return copyTriples(c.sortedPartitions())
+}
+
+// sortedPartitions returns the cached sorted partitions, which must not be modified
+func (c *LeftTripleSet[S1, S2, S3]) sortedPartitions() []Triple[S1, S2, S3] {
+	return c.sorted.get(func() []Triple[S1, S2, S3] { return sortedTriples(partitionsMap(c, Triple[S1, S2, S3].ID)) })
+}
+
+// sortedTriples sorts the triples by CompareTriples, and returns them
+func sortedTriples[S1 Set[S1], S2 Set[S2], S3 Set[S3]](triples []Triple[S1, S2, S3]) []Triple[S1, S2, S3] {
+	slices.SortFunc(triples, CompareTriples)
+	return triples
 }
 
 func MapTripleSet[S1 Set[S1], S2 Set[S2], S3 Set[S3], T1 Set[T1], T2 Set[T2], T3 Set[T3]](c TripleSet[S1, S2, S3],
@@ -6,10 +6,16 @@ SPDX-License-Identifier: Apache-2.0
 
 package ds
 
+import (
+	"math/big"
+	"slices"
+)
+
 // OuterTripleSet is an outer-associative 3-product of sets (S1 x S3) x S2,
 // created as LeftTripleSet[S1, S3, S2] (Product[Product[S1, S3], S2])
 type OuterTripleSet[S1 Set[S1], S2 Set[S2], S3 Set[S3]] struct {
-	m *LeftTripleSet[S1, S3, S2]
+	m      *LeftTripleSet[S1, S3, S2]
+	sorted sortedCache[Triple[S1, S2, S3]]
 }
 
 func NewOuterTripleSet[S1 Set[S1], S2 Set[S2], S3 Set[S3]]() *OuterTripleSet[S1, S2, S3] {
@@ -28,6 +34,12 @@ func (c *OuterTripleSet[S1, S2, S3]) Copy() TripleSet[S1, S2, S3] {
 	return &OuterTripleSet[S1, S2, S3]{m: c.m.Copy().(*LeftTripleSet[S1, S3, S2])}
 }
 
+// Compare returns -1 if c<other, 1 if c>other, 0 o.w.
+// Triple sets are ordered lexicographically by their sorted partitions.
+func (c *OuterTripleSet[S1, S2, S3]) Compare(other TripleSet[S1, S2, S3]) int {
+	return slices.CompareFunc(c.sortedPartitions(), AsOuterTripleSet(other).sortedPartitions(), CompareTriples[S1, S2, S3])
+}
+
 func (c *OuterTripleSet[S1, S2, S3]) Hash() int {
 	return c.m.Hash()
 }
@@ -40,6 +52,11 @@ func (c *OuterTripleSet[S1, S2, S3]) Size() int {
 	return c.m.Size()
 }
 
+// BigSize returns the exact number of triples in the set
+func (c *OuterTripleSet[S1, S2, S3]) BigSize() *big.Int {
+	return c.m.BigSize()
+}
+
 func AsOuterTripleSet[S1 Set[S1], S2 Set[S2], S3 Set[S3]](other TripleSet[S1, S2, S3]) *OuterTripleSet[S1, S2, S3] {
 	r, ok := other.(*OuterTripleSet[S1, S2, S3])
 	if ok {
@@ -70,7 +87,12 @@ func (c *OuterTripleSet[S1, S2, S3]) Subtract(other TripleSet[S1, S2, S3]) Tripl
 }
 
 func (c *OuterTripleSet[S1, S2, S3]) Partitions() []Triple[S1, S2, S3] {
-	return partitionsMap(c.m, Triple[S1, S3, S2].Swap23)
+	return copyTriples(c.sortedPartitions())
+}
+
+// sortedPartitions returns the cached sorted partitions, which must not be modified
+func (c *OuterTripleSet[S1, S2, S3]) sortedPartitions() []Triple[S1, S2, S3] {
+	return c.sorted.get(func() []Triple[S1, S2, S3] { return sortedTriples(partitionsMap(c.m, Triple[S1, S3, S2].Swap23)) })
 }
 
 func (c *OuterTripleSet[S1, S2, S3]) String() string {
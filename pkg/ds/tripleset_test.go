@@ -547,3 +547,22 @@ func TestCartesianLeftTriple(t *testing.T) {
 	require.True(t, !z4.IsEmpty())
 	fmt.Println(z1) // {}
 }
+
+func TestTripleSetPartitionsOrder(t *testing.T) {
+	for _, c := range []ds.TripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *interval.CanonicalSet]{
+		cubioidLeft(30, 40, 1, 5, 1, 1).Union(cubioidLeft(1, 10, 6, 8, 2, 2)).Union(cubioidLeft(1, 10, 1, 5, 3, 3)),
+		cubioidRight(30, 40, 1, 5, 1, 1).Union(cubioidRight(1, 10, 6, 8, 2, 2)).Union(cubioidRight(1, 10, 1, 5, 3, 3)),
+		cubioidOuter(30, 40, 1, 5, 1, 1).Union(cubioidOuter(1, 10, 6, 8, 2, 2)).Union(cubioidOuter(1, 10, 1, 5, 3, 3)),
+	} {
+		partitions := c.Partitions()
+		for i := range partitions[1:] {
+			require.Equal(t, -1, ds.CompareTriples(partitions[i], partitions[i+1]))
+		}
+		require.Equal(t, 0, c.Compare(c.Copy()))
+		require.Equal(t, -1, cubioidLeft(1, 10, 1, 5, 3, 3).Compare(c))
+
+		// the cached partitions are copied; modifying the returned ones does not affect the set
+		partitions[0].S1.AddInterval(interval.New(50, 50))
+		require.Equal(t, "1-10", c.Partitions()[0].S1.String())
+	}
+}
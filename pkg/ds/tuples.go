@@ -6,6 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 
 package ds
 
+import "sync/atomic"
+
 type Pair[L, R any] struct {
 	Left  L
 	Right R
@@ -16,3 +18,46 @@ type Triple[S1, S2, S3 any] struct {
 	S2 S2
 	S3 S3
 }
+
+// ComparePairs compares pairs of sets lexicographically: by their left sets, and then by their right sets
+func ComparePairs[L Set[L], R Set[R]](a, b Pair[L, R]) int {
+	if c := Compare(a.Left, b.Left); c != 0 {
+		return c
+	}
+	return Compare(a.Right, b.Right)
+}
+
+// CompareTriples compares triples of sets lexicographically: by S1, then by S2, and then by S3
+func CompareTriples[S1 Set[S1], S2 Set[S2], S3 Set[S3]](a, b Triple[S1, S2, S3]) int {
+	if c := Compare(a.S1, b.S1); c != 0 {
+		return c
+	}
+	if c := Compare(a.S2, b.S2); c != 0 {
+		return c
+	}
+	return Compare(a.S3, b.S3)
+}
+
+// sortedCache holds the sorted partitions of an immutable set, computed on first use.
+// It is safe for concurrent use: concurrent first uses compute the same partitions.
+type sortedCache[T any] struct {
+	p atomic.Pointer[[]T]
+}
+
+func (c *sortedCache[T]) get(compute func() []T) []T {
+	if p := c.p.Load(); p != nil {
+		return *p
+	}
+	res := compute()
+	c.p.Store(&res)
+	return res
+}
+
+// copyTriples returns copies of the given triples
+func copyTriples[S1 Set[S1], S2 Set[S2], S3 Set[S3]](triples []Triple[S1, S2, S3]) []Triple[S1, S2, S3] {
+	res := make([]Triple[S1, S2, S3], len(triples))
+	for i, t := range triples {
+		res[i] = Triple[S1, S2, S3]{S1: t.S1.Copy(), S2: t.S2.Copy(), S3: t.S3.Copy()}
+	}
+	return res
+}
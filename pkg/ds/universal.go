@@ -0,0 +1,76 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds
+
+import "math/big"
+
+// The functions below derive the universe of products, triple sets and disjoint sums from the universes of
+// their components. Since Go does not support creating a value of a generic type, the functions get input
+// sets of the component types, whose Universe() method is used; their content is ignored.
+//
+// The product types cannot implement Universal themselves: they are generic over any Set, and Go does not allow
+// a method to further constrain the type parameters of its receiver, so a method of ProductLeft[S1, S2] cannot call
+// S1.Universe(). Moreover, an empty product holds no value of S1 or S2 whose Universe() method could be used.
+// Types built on products with Universal components (e.g., netset.EndpointsTrafficSet) implement Universal by
+// these functions, passing empty sets of their components.
+
+// ProductUniverse returns the product of the universes of S1 and S2, given input of (empty) sets in S1 and S2.
+func ProductUniverse[S1 Universal[S1], S2 Universal[S2]](empty1 S1, empty2 S2) Product[S1, S2] {
+	return CartesianPairLeft(empty1.Universe(), empty2.Universe())
+}
+
+// ProductComplement returns the pairs of ProductUniverse that are not in p, given input of (empty) sets in S1 and S2.
+func ProductComplement[S1 Universal[S1], S2 Universal[S2]](p Product[S1, S2], empty1 S1, empty2 S2) Product[S1, S2] {
+	return ProductUniverse(empty1, empty2).Subtract(p)
+}
+
+// ProductIsAll returns true if p equals ProductUniverse, given input of (empty) sets in S1 and S2.
+func ProductIsAll[S1 Universal[S1], S2 Universal[S2]](p Product[S1, S2], empty1 S1, empty2 S2) bool {
+	return p.Equal(ProductUniverse(empty1, empty2))
+}
+
+// TripleUniverse returns the 3-product of the universes of S1, S2 and S3, given input of (empty) sets in S1, S2 and S3.
+func TripleUniverse[S1 Universal[S1], S2 Universal[S2], S3 Universal[S3]](empty1 S1, empty2 S2, empty3 S3) TripleSet[S1, S2, S3] {
+	return CartesianLeftTriple(empty1.Universe(), empty2.Universe(), empty3.Universe())
+}
+
+// TripleComplement returns the triples of TripleUniverse that are not in t, given input of (empty) sets in S1, S2 and S3.
+func TripleComplement[S1 Universal[S1], S2 Universal[S2], S3 Universal[S3]](t TripleSet[S1, S2, S3],
+	empty1 S1, empty2 S2, empty3 S3) TripleSet[S1, S2, S3] {
+	return TripleUniverse(empty1, empty2, empty3).Subtract(t)
+}
+
+// TripleIsAll returns true if t equals TripleUniverse, given input of (empty) sets in S1, S2 and S3.
+func TripleIsAll[S1 Universal[S1], S2 Universal[S2], S3 Universal[S3]](t TripleSet[S1, S2, S3],
+	empty1 S1, empty2 S2, empty3 S3) bool {
+	return t.Equal(TripleUniverse(empty1, empty2, empty3))
+}
+
+// Fraction returns the size of s relative to the size of its universe, e.g., 1/2 for the even numbers of [0, 7].
+// The fraction of a set with an empty universe is 0.
+func Fraction[S Universal[S]](s S) *big.Rat {
+	universe := BigSize(s.Universe())
+	if universe.Sign() == 0 {
+		return new(big.Rat)
+	}
+	return new(big.Rat).SetFrac(BigSize(s), universe)
+}
+
+// DisjointUniverse returns the disjoint sum of the universes of L and R
+func DisjointUniverse[L Universal[L], R Universal[R]](d *Disjoint[L, R]) *Disjoint[L, R] {
+	return NewDisjoint(d.left.Universe(), d.right.Universe())
+}
+
+// DisjointComplement returns the elements of DisjointUniverse that are not in d
+func DisjointComplement[L Universal[L], R Universal[R]](d *Disjoint[L, R]) *Disjoint[L, R] {
+	return NewDisjoint(d.left.Complement(), d.right.Complement())
+}
+
+// DisjointIsAll returns true if d equals DisjointUniverse
+func DisjointIsAll[L Universal[L], R Universal[R]](d *Disjoint[L, R]) bool {
+	return d.left.IsAll() && d.right.IsAll()
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ds_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/ds/settest"
+)
+
+func TestProductComplement(t *testing.T) {
+	p := ds.CartesianPairLeft(settest.Bits(0b11), settest.Bits(0b1))
+	complement := ds.ProductComplement[settest.Bits, settest.Bits](p, 0, 0)
+	require.Equal(t, 64-2, complement.Size())
+	require.True(t, complement.Intersect(p).IsEmpty())
+	require.True(t, ds.ProductIsAll(complement.Union(p), settest.Bits(0), settest.Bits(0)))
+	require.False(t, ds.ProductIsAll[settest.Bits, settest.Bits](p, 0, 0))
+}
+
+func TestTripleComplement(t *testing.T) {
+	c := ds.CartesianLeftTriple(settest.Bits(0b11), settest.Bits(0b1), settest.Bits(0b1111))
+	complement := ds.TripleComplement[settest.Bits, settest.Bits, settest.Bits](c, 0, 0, 0)
+	require.Equal(t, 512-8, complement.Size())
+	require.True(t, ds.TripleIsAll(complement.Union(c), settest.Bits(0), settest.Bits(0), settest.Bits(0)))
+	require.True(t, ds.TripleUniverse(settest.Bits(0), settest.Bits(0), settest.Bits(0)).Subtract(complement).Equal(c))
+}
+
+func TestDisjointComplement(t *testing.T) {
+	d := ds.NewDisjoint(settest.Bits(0b1), settest.Bits(0))
+	complement := ds.DisjointComplement(d)
+	require.Equal(t, 7+8, complement.Size())
+	require.True(t, ds.DisjointIsAll(complement.Union(d)))
+	require.True(t, ds.DisjointUniverse(d).Equal(complement.Union(d)))
+}
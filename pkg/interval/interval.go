@@ -6,7 +6,10 @@ SPDX-License-Identifier: Apache-2.0
 
 package interval
 
-import "fmt"
+import (
+	"cmp"
+	"fmt"
+)
 
 // Interval is an integer interval from start to end inclusive.
 // An empty interval is represented by [-1, 0].
@@ -63,6 +66,14 @@ func (i Interval) Equal(x Interval) bool {
 	return i.start == x.start && i.end == x.end
 }
 
+// Compare returns -1 if i<x, 1 if i>x, 0 o.w., ordering intervals by their start and then by their end
+func (i Interval) Compare(x Interval) int {
+	if c := cmp.Compare(i.start, x.start); c != 0 {
+		return c
+	}
+	return cmp.Compare(i.end, x.end)
+}
+
 func (i Interval) Size() int64 {
 	return i.end - i.start + 1
 }
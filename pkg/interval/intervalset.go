@@ -9,6 +9,8 @@ package interval
 import (
 	"log"
 	"math"
+	"math/big"
+	"math/rand/v2"
 	"slices"
 	"sort"
 )
@@ -71,6 +73,38 @@ func (c *CanonicalSet) Size() int {
 	return int(res)
 }
 
+// BigSize returns the exact number of integers in the set, which may exceed int64
+func (c *CanonicalSet) BigSize() *big.Int {
+	res := new(big.Int)
+	for _, r := range c.intervalSet {
+		res.Add(res, new(big.Int).Sub(big.NewInt(r.End()), big.NewInt(r.Start())))
+		res.Add(res, big.NewInt(1))
+	}
+	return res
+}
+
+// Sample returns an element of the set, chosen uniformly at random. It panics if the set is empty.
+func (c *CanonicalSet) Sample(r *rand.Rand) int64 {
+	if c.IsEmpty() {
+		log.Panic("cannot sample from an empty set")
+	}
+	var i uint64
+	if size := c.BigSize(); size.IsUint64() {
+		i = r.Uint64N(size.Uint64())
+	} else {
+		i = r.Uint64() // the set holds all the 2^64 values of int64
+	}
+	// offsets are computed in uint64, where they do not overflow even for intervals of more than 2^63 values
+	for _, v := range c.intervalSet {
+		last := uint64(v.End()) - uint64(v.Start()) //nolint:gosec // conversions between int64 and uint64 wrap around, as intended
+		if i <= last {
+			return int64(uint64(v.Start()) + i) //nolint:gosec // see above
+		}
+		i -= last + 1
+	}
+	return c.Max() // not reached
+}
+
 // Equal returns true if the CanonicalSet equals the input CanonicalSet
 func (c *CanonicalSet) Equal(other *CanonicalSet) bool {
 	if c == other {
@@ -87,6 +121,12 @@ func (c *CanonicalSet) Equal(other *CanonicalSet) bool {
 	return true
 }
 
+// Compare returns -1 if c<other, 1 if c>other, 0 o.w.
+// Sets are ordered lexicographically by their intervals, so the empty set is the smallest set.
+func (c *CanonicalSet) Compare(other *CanonicalSet) int {
+	return slices.CompareFunc(c.intervalSet, other.intervalSet, Interval.Compare)
+}
+
 // AddInterval adds a new interval range to the set
 func (c *CanonicalSet) AddInterval(v Interval) {
 	if v.IsEmpty() {
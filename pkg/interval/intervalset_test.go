@@ -7,10 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package interval_test
 
 import (
+	"math"
+	"math/big"
+	"math/rand/v2"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/np-guard/models/pkg/ds/settest"
 	"github.com/np-guard/models/pkg/interval"
 )
 
@@ -56,6 +60,35 @@ func TestIntervalSet(t *testing.T) {
 	require.True(t, interval.New(1, 1).ToSet().IsSingleNumber())
 }
 
+func TestIntervalSetSample(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	s := interval.New(0, 0).ToSet().Union(interval.New(10, 19).ToSet())
+	zeros := 0
+	for range 11000 {
+		v := s.Sample(r)
+		require.True(t, s.Contains(v))
+		if v == 0 {
+			zeros++
+		}
+	}
+	require.InDelta(t, 1000, zeros, 100)
+
+	all := interval.New(math.MinInt64, math.MaxInt64).ToSet()
+	require.True(t, all.Contains(all.Sample(r)))
+	edges := interval.New(math.MinInt64, math.MinInt64).ToSet().Union(interval.New(math.MaxInt64, math.MaxInt64).ToSet())
+	require.True(t, edges.Contains(edges.Sample(r)))
+	require.Panics(t, func() { interval.NewCanonicalSet().Sample(r) })
+}
+
+func TestIntervalSetBigSize(t *testing.T) {
+	s := interval.New(0, 9).ToSet().Union(interval.New(20, 29).ToSet())
+	require.Equal(t, int64(20), s.BigSize().Int64())
+	require.Zero(t, interval.NewCanonicalSet().BigSize().Sign())
+
+	all := interval.New(math.MinInt64, math.MaxInt64).ToSet()
+	require.Zero(t, new(big.Int).Lsh(big.NewInt(1), 64).Cmp(all.BigSize()))
+}
+
 func TestIntervalSetSubtract(t *testing.T) {
 	s := interval.New(1, 100).ToSet()
 	s.AddInterval(interval.New(400, 700))
@@ -65,3 +98,21 @@ func TestIntervalSetSubtract(t *testing.T) {
 	expected := interval.New(1, 49).ToSet()
 	require.Equal(t, expected.Elements(), actual.Elements())
 }
+
+// randomCanonicalSet returns a union of a few short intervals in [0, 20]
+func randomCanonicalSet(r *rand.Rand) *interval.CanonicalSet {
+	res := interval.NewCanonicalSet()
+	for range r.IntN(4) {
+		start := r.Int64N(16)
+		res.AddInterval(interval.New(start, start+r.Int64N(5)))
+	}
+	return res
+}
+
+func TestCanonicalSetLaws(t *testing.T) {
+	settest.Check(t, randomCanonicalSet, 500)
+}
+
+func FuzzCanonicalSetLaws(f *testing.F) {
+	settest.Fuzz(f, randomCanonicalSet)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interval
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MapEntry is a single interval of a Map, with the value all its numbers are mapped to
+type MapEntry[V any] struct {
+	Interval Interval
+	Value    V
+}
+
+// Map is a mapping from int64 integers to values of type V, implemented using an ordered slice of
+// non-overlapping intervals, each mapped to a single value. Touching intervals mapped to equal values are merged.
+type Map[V any] struct {
+	entries []MapEntry[V]
+	equal   func(V, V) bool
+}
+
+// NewMap returns an empty Map, comparing values using ==
+func NewMap[V comparable]() *Map[V] {
+	return NewMapFunc(func(a, b V) bool { return a == b })
+}
+
+// NewMapFunc returns an empty Map, comparing values using the given equality function
+func NewMapFunc[V any](equal func(V, V) bool) *Map[V] {
+	return &Map[V]{entries: []MapEntry[V]{}, equal: equal}
+}
+
+// Entries returns the intervals of the map, ordered by their start, each with its value
+func (m *Map[V]) Entries() []MapEntry[V] {
+	return slices.Clone(m.entries)
+}
+
+// NumEntries returns the number of intervals in the map
+func (m *Map[V]) NumEntries() int {
+	return len(m.entries)
+}
+
+// IsEmpty returns true if the map is empty
+func (m *Map[V]) IsEmpty() bool {
+	return len(m.entries) == 0
+}
+
+// Copy returns a new copy of the Map object. Values are copied shallowly.
+func (m *Map[V]) Copy() *Map[V] {
+	return &Map[V]{entries: slices.Clone(m.entries), equal: m.equal}
+}
+
+// Equal returns true if both maps map the same numbers to equal values
+func (m *Map[V]) Equal(other *Map[V]) bool {
+	return slices.EqualFunc(m.entries, other.entries, func(a, b MapEntry[V]) bool {
+		return a.Interval.Equal(b.Interval) && m.equal(a.Value, b.Value)
+	})
+}
+
+// Domain returns the set of numbers that are mapped to some value
+func (m *Map[V]) Domain() *CanonicalSet {
+	res := NewCanonicalSet()
+	for _, e := range m.entries {
+		res.AddInterval(e.Interval)
+	}
+	return res
+}
+
+// At returns the value n is mapped to, and whether such a value exists
+func (m *Map[V]) At(n int64) (res V, ok bool) {
+	i := sort.Search(len(m.entries), func(i int) bool {
+		return m.entries[i].Interval.End() >= n
+	})
+	if i < len(m.entries) && m.entries[i].Interval.Start() <= n {
+		return m.entries[i].Value, true
+	}
+	return res, false
+}
+
+// Intersecting returns the entries of the map that overlap span, trimmed to span
+func (m *Map[V]) Intersecting(span Interval) []MapEntry[V] {
+	var res []MapEntry[V]
+	lo, hi := m.overlapping(span)
+	for _, e := range m.entries[lo:hi] {
+		res = append(res, MapEntry[V]{Interval: e.Interval.Intersect(span), Value: e.Value})
+	}
+	return res
+}
+
+// Set maps all the numbers in span to v, overriding previous values
+func (m *Map[V]) Set(span Interval, v V) {
+	m.update(span, func(V, bool) (V, bool) { return v, true })
+}
+
+// Insert maps all the numbers in span to v. Numbers already mapped to a value are mapped to merge(old, v) instead.
+func (m *Map[V]) Insert(span Interval, v V, merge func(old, v V) V) {
+	m.update(span, func(old V, found bool) (V, bool) {
+		if found {
+			return merge(old, v), true
+		}
+		return v, true
+	})
+}
+
+// Delete removes all the numbers in span from the map
+func (m *Map[V]) Delete(span Interval) {
+	m.update(span, func(old V, _ bool) (V, bool) { return old, false })
+}
+
+// overlapping returns the range [lo, hi) of entries overlapping span
+func (m *Map[V]) overlapping(span Interval) (lo, hi int) {
+	lo = sort.Search(len(m.entries), func(i int) bool {
+		return m.entries[i].Interval.End() >= span.Start()
+	})
+	hi = sort.Search(len(m.entries), func(i int) bool {
+		return m.entries[i].Interval.Start() > span.End()
+	})
+	return lo, max(lo, hi)
+}
+
+// update replaces the values of all the numbers in span by f(old value, whether old value exists).
+// If f returns false as its second value, the numbers are removed from the map.
+func (m *Map[V]) update(span Interval, f func(V, bool) (V, bool)) {
+	if span.IsEmpty() {
+		return
+	}
+	lo, hi := m.overlapping(span)
+	var pieces []MapEntry[V]
+	add := func(i Interval, v V, keep bool) {
+		if keep && !i.IsEmpty() {
+			pieces = append(pieces, MapEntry[V]{Interval: i, Value: v})
+		}
+	}
+	var zero V
+	cursor := span.Start()
+	done := false
+	for _, e := range m.entries[lo:hi] {
+		if e.Interval.Start() < span.Start() {
+			add(New(e.Interval.Start(), span.Start()-1), e.Value, true)
+		}
+		if e.Interval.Start() > cursor {
+			v, keep := f(zero, false)
+			add(New(cursor, e.Interval.Start()-1), v, keep)
+		}
+		common := e.Interval.Intersect(span)
+		v, keep := f(e.Value, true)
+		add(common, v, keep)
+		if e.Interval.End() > span.End() {
+			add(New(span.End()+1, e.Interval.End()), e.Value, true)
+		}
+		if common.End() == span.End() {
+			done = true
+		} else {
+			cursor = common.End() + 1
+		}
+	}
+	if !done {
+		v, keep := f(zero, false)
+		add(New(cursor, span.End()), v, keep)
+	}
+	m.entries = slices.Replace(m.entries, lo, hi, pieces...)
+	m.coalesce(max(lo-1, 0), min(lo+len(pieces)+1, len(m.entries)))
+}
+
+// coalesce merges touching entries with equal values in the range [from, to)
+func (m *Map[V]) coalesce(from, to int) {
+	i := from
+	for i+1 < to {
+		cur, next := m.entries[i], m.entries[i+1]
+		if cur.Interval.End()+1 == next.Interval.Start() && m.equal(cur.Value, next.Value) {
+			m.entries[i].Interval = New(cur.Interval.Start(), next.Interval.End())
+			m.entries = slices.Delete(m.entries, i+1, i+2)
+			to--
+			continue
+		}
+		i++
+	}
+}
+
+// String returns a string representation of the map: a comma-separated list of interval:value
+func (m *Map[V]) String() string {
+	if m.IsEmpty() {
+		return "Empty"
+	}
+	res := make([]string, len(m.entries))
+	for i, e := range m.entries {
+		res[i] = fmt.Sprintf("%s:%v", e.Interval.ShortString(), e.Value)
+	}
+	return strings.Join(res, ",")
+}
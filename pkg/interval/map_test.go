@@ -0,0 +1,80 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interval_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/interval"
+)
+
+func TestMapSet(t *testing.T) {
+	m := interval.NewMap[string]()
+	require.True(t, m.IsEmpty())
+	m.Set(interval.New(1, 100), "a")
+	m.Set(interval.New(40, 60), "b")
+	fmt.Println(m) // 1-39:a,40-60:b,61-100:a
+	require.Equal(t, "1-39:a,40-60:b,61-100:a", m.String())
+
+	v, ok := m.At(50)
+	require.True(t, ok)
+	require.Equal(t, "b", v)
+	_, ok = m.At(101)
+	require.False(t, ok)
+
+	// setting back to the same value merges the intervals
+	m.Set(interval.New(40, 60), "a")
+	require.Equal(t, "1-100:a", m.String())
+
+	// touching intervals with equal values are merged
+	m.Set(interval.New(101, 120), "a")
+	m.Set(interval.New(200, 210), "a")
+	require.Equal(t, "1-120:a,200-210:a", m.String())
+	require.True(t, m.Domain().Equal(interval.New(1, 120).ToSet().Union(interval.New(200, 210).ToSet())))
+}
+
+func TestMapInsertMerge(t *testing.T) {
+	m := interval.NewMap[int]()
+	sum := func(old, v int) int { return old + v }
+	m.Insert(interval.New(0, 10), 1, sum)
+	m.Insert(interval.New(5, 20), 2, sum)
+	m.Insert(interval.New(30, 40), 2, sum)
+	require.Equal(t, "0-4:1,5-10:3,11-20:2,30-40:2", m.String())
+
+	m.Insert(interval.New(0, 50), 1, sum)
+	require.Equal(t, "0-4:2,5-10:4,11-20:3,21-29:1,30-40:3,41-50:1", m.String())
+	require.Equal(t, []interval.MapEntry[int]{
+		{Interval: interval.New(8, 10), Value: 4},
+		{Interval: interval.New(11, 12), Value: 3},
+	}, m.Intersecting(interval.New(8, 12)))
+}
+
+func TestMapDelete(t *testing.T) {
+	m := interval.NewMap[string]()
+	m.Set(interval.New(1, 10), "a")
+	m.Set(interval.New(11, 20), "b")
+	m.Delete(interval.New(5, 15))
+	require.Equal(t, "1-4:a,16-20:b", m.String())
+	m.Delete(interval.New(0, 100))
+	require.True(t, m.IsEmpty())
+}
+
+func TestMapFunc(t *testing.T) {
+	m := interval.NewMapFunc((*interval.CanonicalSet).Equal)
+	m.Set(interval.New(1, 10), interval.New(1, 1).ToSet())
+	m.Set(interval.New(11, 20), interval.New(1, 1).ToSet())
+	require.Equal(t, 1, m.NumEntries())
+
+	other := m.Copy()
+	require.True(t, other.Equal(m))
+	other.Set(interval.New(5, 5), interval.New(2, 2).ToSet())
+	require.False(t, other.Equal(m))
+	require.Equal(t, 3, other.NumEntries())
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ICMPTypeCodeSeparator separates the type from the code in the symbolic form of an ICMP value (e.g., "dest-unreachable/port-unreachable")
+const ICMPTypeCodeSeparator = "/"
+
+// typeNames maps each ICMP type defined in RFC 792 to its symbolic name
+var typeNames = map[int]string{
+	EchoReply:              "echo-reply",
+	DestinationUnreachable: "dest-unreachable",
+	SourceQuench:           "source-quench",
+	Redirect:               "redirect",
+	Echo:                   "echo-request",
+	TimeExceeded:           "time-exceeded",
+	ParameterProblem:       "parameter-problem",
+	Timestamp:              "timestamp-request",
+	TimestampReply:         "timestamp-reply",
+	InformationRequest:     "info-request",
+	InformationReply:       "info-reply",
+}
+
+// codeNames maps each ICMP type with more than a single code to the symbolic names of its codes, indexed by code
+var codeNames = map[int][]string{
+	DestinationUnreachable: {
+		"net-unreachable",
+		"host-unreachable",
+		"protocol-unreachable",
+		"port-unreachable",
+		"fragmentation-needed",
+		"source-route-failed",
+	},
+	Redirect: {
+		"network-redirect",
+		"host-redirect",
+		"tos-network-redirect",
+		"tos-host-redirect",
+	},
+	TimeExceeded: {
+		"ttl-exceeded",
+		"reassembly-timeout",
+	},
+}
+
+// ICMPTypeName returns the symbolic name of an ICMP type, if it is defined in RFC 792
+func ICMPTypeName(t int) (string, bool) {
+	name, ok := typeNames[t]
+	return name, ok
+}
+
+// ICMPCodeName returns the symbolic name of an ICMP code of the given type, if it is defined in RFC 792.
+// Codes of types with a single code have no name.
+func ICMPCodeName(t, code int) (string, bool) {
+	names := codeNames[t]
+	if code < 0 || code >= len(names) {
+		return "", false
+	}
+	return names[code], true
+}
+
+// ICMPTypeByName returns the ICMP type with the given symbolic name
+func ICMPTypeByName(name string) (int, bool) {
+	for t, typeName := range typeNames {
+		if typeName == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// ICMPCodeByName returns the code of the given ICMP type with the given symbolic name
+func ICMPCodeByName(t int, name string) (int, bool) {
+	for code, codeName := range codeNames[t] {
+		if codeName == name {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// ParseICMPType parses an ICMP type, given either as a symbolic name or as a number
+func ParseICMPType(s string) (int, error) {
+	if t, ok := ICMPTypeByName(s); ok {
+		return t, nil
+	}
+	t, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("unknown ICMP type %q", s)
+	}
+	return t, nil
+}
+
+// ParseICMPCode parses a code of the given ICMP type, given either as a symbolic name or as a number
+func ParseICMPCode(t int, s string) (int, error) {
+	if code, ok := ICMPCodeByName(t, s); ok {
+		return code, nil
+	}
+	code, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("unknown ICMP code %q for ICMP type %d", s, t)
+	}
+	return code, nil
+}
+
+// ParseICMP parses an RFC-valid ICMP value, given in the form "type" or "type/code", where the type and the code are
+// either symbolic names or numbers. For example, "echo-request", "dest-unreachable/port-unreachable" and "3/3".
+func ParseICMP(s string) (ICMP, error) {
+	typeStr, codeStr, hasCode := strings.Cut(strings.TrimSpace(s), ICMPTypeCodeSeparator)
+	t, err := ParseICMPType(typeStr)
+	if err != nil {
+		return ICMP{}, err
+	}
+	if !hasCode {
+		return NewICMP(&ICMPTypeCode{Type: t})
+	}
+	code, err := ParseICMPCode(t, codeStr)
+	if err != nil {
+		return ICMP{}, err
+	}
+	return NewICMP(&ICMPTypeCode{Type: t, Code: &code})
+}
+
+// String returns the symbolic form of the ICMP value: "type" if all its codes are allowed, and "type/code" otherwise.
+// Types and codes without a symbolic name are written as numbers.
+func (t ICMP) String() string {
+	if t.TypeCode == nil {
+		return string(ProtocolStringICMP)
+	}
+	res := strconv.Itoa(t.TypeCode.Type)
+	if name, ok := ICMPTypeName(t.TypeCode.Type); ok {
+		res = name
+	}
+	if t.TypeCode.Code == nil {
+		return res
+	}
+	code := strconv.Itoa(*t.TypeCode.Code)
+	if name, ok := ICMPCodeName(t.TypeCode.Type, *t.TypeCode.Code); ok {
+		code = name
+	}
+	return res + ICMPTypeCodeSeparator + code
+}
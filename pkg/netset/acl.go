@@ -0,0 +1,149 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import "slices"
+
+// ACLAction is the action an ACL takes on the traffic matched by a rule
+type ACLAction string
+
+const (
+	Allow ACLAction = "allow"
+	Deny  ACLAction = "deny"
+)
+
+// ACLRule is a single rule of an ACL, matching all traffic from Src to Dst over Conn
+type ACLRule struct {
+	Name   string
+	Action ACLAction
+	Src    *IPBlock
+	Dst    *IPBlock
+	Conn   *TransportSet
+}
+
+// Traffic returns all the traffic matched by the rule, regardless of rules preceding it
+func (r *ACLRule) Traffic() *EndpointsTrafficSet {
+	return NewEndpointsTrafficSet(r.Src, r.Dst, r.Conn)
+}
+
+// ACL is an ordered list of rules, evaluated using first-match semantics:
+// the action on each connection is the action of the first rule that matches it,
+// or the default action if no rule matches it.
+type ACL struct {
+	Rules         []*ACLRule
+	DefaultAction ACLAction
+}
+
+// NewACL returns a new ACL with the given default action and rules
+func NewACL(defaultAction ACLAction, rules ...*ACLRule) *ACL {
+	return &ACL{Rules: rules, DefaultAction: defaultAction}
+}
+
+// AddRule appends a rule to the end of the ACL
+func (a *ACL) AddRule(name string, action ACLAction, src, dst *IPBlock, conn *TransportSet) {
+	a.Rules = append(a.Rules, &ACLRule{Name: name, Action: action, Src: src, Dst: dst, Conn: conn})
+}
+
+func allTraffic() *EndpointsTrafficSet {
+	return NewEndpointsTrafficSet(GetCidrAll(), GetCidrAll(), AllTransports())
+}
+
+// Decided returns, for each rule, the traffic it actually decides: the traffic it matches,
+// minus the traffic matched by the rules preceding it. The result is aligned with a.Rules.
+func (a *ACL) Decided() []*EndpointsTrafficSet {
+	res := make([]*EndpointsTrafficSet, len(a.Rules))
+	matched := EmptyEndpointsTrafficSet()
+	for i, rule := range a.Rules {
+		traffic := rule.Traffic()
+		res[i] = traffic.Subtract(matched)
+		matched = matched.Union(traffic)
+	}
+	return res
+}
+
+// DecidedByDefault returns the traffic not matched by any rule, to which the default action applies
+func (a *ACL) DecidedByDefault() *EndpointsTrafficSet {
+	res := allTraffic()
+	for _, rule := range a.Rules {
+		res = res.Subtract(rule.Traffic())
+	}
+	return res
+}
+
+// Allowed returns all the traffic allowed by the ACL
+func (a *ACL) Allowed() *EndpointsTrafficSet {
+	return a.withAction(Allow)
+}
+
+// Denied returns all the traffic denied by the ACL
+func (a *ACL) Denied() *EndpointsTrafficSet {
+	return a.withAction(Deny)
+}
+
+func (a *ACL) withAction(action ACLAction) *EndpointsTrafficSet {
+	res := EmptyEndpointsTrafficSet()
+	for i, decided := range a.Decided() {
+		if a.Rules[i].Action == action {
+			res = res.Union(decided)
+		}
+	}
+	if a.DefaultAction == action {
+		res = res.Union(a.DecidedByDefault())
+	}
+	return res
+}
+
+// Shadowed returns the rules that decide no traffic, since all the traffic they match is matched by preceding rules
+func (a *ACL) Shadowed() []*ACLRule {
+	var res []*ACLRule
+	for i, decided := range a.Decided() {
+		if decided.IsEmpty() {
+			res = append(res, a.Rules[i])
+		}
+	}
+	return res
+}
+
+// Redundant returns rules that decide some traffic, but removing them would not change the ACL's decisions,
+// since the traffic they decide would get the same action from the following rules or from the default action.
+// The rules are checked in order, each in the ACL without the redundant rules preceding it, so that removing all the
+// returned rules together does not change the ACL's decisions either.
+func (a *ACL) Redundant() []*ACLRule {
+	var res []*ACLRule
+	current := NewACL(a.DefaultAction, slices.Clone(a.Rules)...)
+	matched := EmptyEndpointsTrafficSet()
+	for i := 0; i < len(current.Rules); {
+		rule := current.Rules[i]
+		decided := rule.Traffic().Subtract(matched)
+		if !decided.IsEmpty() && current.sameActionAfter(i, decided) {
+			// removing the rule changes only the traffic decided by the following rules, which are not checked yet
+			res = append(res, rule)
+			current.Rules = slices.Delete(current.Rules, i, i+1)
+			continue
+		}
+		matched = matched.Union(rule.Traffic())
+		i++
+	}
+	return res
+}
+
+// sameActionAfter returns true if the rules following the i'th rule, and the default action,
+// take the i'th rule's action on all the given traffic
+func (a *ACL) sameActionAfter(i int, traffic *EndpointsTrafficSet) bool {
+	action := a.Rules[i].Action
+	for _, rule := range a.Rules[i+1:] {
+		matched := traffic.Intersect(rule.Traffic())
+		if matched.IsEmpty() {
+			continue
+		}
+		if rule.Action != action {
+			return false
+		}
+		traffic = traffic.Subtract(matched)
+	}
+	return traffic.IsEmpty() || a.DefaultAction == action
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func TestACL(t *testing.T) {
+	subnet1, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	subnet2, _ := netset.IPBlockFromCidr("10.240.20.0/24")
+	host, _ := netset.IPBlockFromIPAddress("10.240.10.5")
+	tcp22 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22)
+
+	acl := netset.NewACL(netset.Deny)
+	acl.AddRule("deny-host", netset.Deny, host, subnet2, netset.AllTransports())
+	acl.AddRule("allow-ssh", netset.Allow, subnet1, subnet2, tcp22)
+	acl.AddRule("allow-host-ssh", netset.Allow, host, subnet2, tcp22)
+	acl.AddRule("allow-tcp", netset.Allow, subnet1, subnet2, netset.AllTCPTransport())
+	acl.AddRule("deny-icmp", netset.Deny, subnet1, subnet2, netset.AllICMPTransport())
+
+	expected := netset.NewEndpointsTrafficSet(subnet1.Subtract(host), subnet2, netset.AllTCPTransport())
+	require.True(t, acl.Allowed().Equal(expected))
+	all := netset.NewEndpointsTrafficSet(netset.GetCidrAll(), netset.GetCidrAll(), netset.AllTransports())
+	require.True(t, acl.Denied().Equal(all.Subtract(expected)))
+
+	decided := acl.Decided()
+	require.Len(t, decided, 5)
+	require.True(t, decided[0].Equal(acl.Rules[0].Traffic()))
+	require.True(t, decided[1].Equal(netset.NewEndpointsTrafficSet(subnet1.Subtract(host), subnet2, tcp22)))
+	require.True(t, decided[2].IsEmpty())
+
+	require.Equal(t, []*netset.ACLRule{acl.Rules[2]}, acl.Shadowed())
+	// allow-ssh is covered by allow-tcp, and deny-icmp by the default action
+	require.Equal(t, []*netset.ACLRule{acl.Rules[1], acl.Rules[4]}, acl.Redundant())
+}
+
+func TestACLMutuallyRedundant(t *testing.T) {
+	subnet1, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	subnet2, _ := netset.IPBlockFromCidr("10.240.20.0/24")
+	tcp22 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22)
+	udp := netset.AllUDPTransport()
+
+	// each of the first two rules is redundant in the full ACL, but removing both would deny SSH
+	acl := netset.NewACL(netset.Deny)
+	acl.AddRule("allow-ssh", netset.Allow, subnet1, subnet2, tcp22)
+	acl.AddRule("allow-ssh-udp", netset.Allow, subnet1, subnet2, tcp22.Union(udp))
+	acl.AddRule("allow-udp", netset.Allow, subnet1, subnet2, udp)
+	redundant := acl.Redundant()
+	require.Equal(t, []*netset.ACLRule{acl.Rules[0]}, redundant)
+
+	var kept []*netset.ACLRule
+	for _, rule := range acl.Rules {
+		if !slices.Contains(redundant, rule) {
+			kept = append(kept, rule)
+		}
+	}
+	require.True(t, netset.NewACL(acl.DefaultAction, kept...).Allowed().Equal(acl.Allowed()))
+}
+
+func TestACLDefaultAllow(t *testing.T) {
+	subnet1, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	acl := netset.NewACL(netset.Allow, &netset.ACLRule{
+		Name: "deny-udp", Action: netset.Deny, Src: subnet1, Dst: netset.GetCidrAll(), Conn: netset.AllUDPTransport(),
+	})
+	require.True(t, acl.Denied().Equal(acl.Rules[0].Traffic()))
+	require.True(t, acl.DecidedByDefault().Equal(acl.Allowed()))
+	require.Empty(t, acl.Shadowed())
+	require.Empty(t, acl.Redundant())
+}
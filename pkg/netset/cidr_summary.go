@@ -0,0 +1,145 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/np-guard/models/pkg/interval"
+)
+
+// SmallestCoveringCidr returns the smallest CIDR that contains all the addresses of b.
+// It returns an error if b is empty.
+func (b *IPBlock) SmallestCoveringCidr() (*IPBlock, error) {
+	if b.IsEmpty() {
+		return nil, errors.New("an empty ipblock has no covering CIDR")
+	}
+	//nolint:gosec // the addresses are valid IPv4 addresses
+	first, last := uint32(b.ipRange.Min()), uint32(b.ipRange.Max())
+	length := bits.LeadingZeros32(first ^ last)
+	mask := prefixMask(length)
+	return cidrBlock(int64(first&mask), int64(^mask)+1), nil
+}
+
+// Summarize returns a block of at most maxPrefixes CIDRs that contains all the addresses of b, with the minimal
+// number of extra addresses, and the extra addresses. Among such blocks, it returns one of the fewest CIDRs.
+// It returns an error if b is not empty and maxPrefixes is not positive.
+func (b *IPBlock) Summarize(maxPrefixes int) (summary, extra *IPBlock, err error) {
+	if b.IsEmpty() {
+		return NewIPBlock(), NewIPBlock(), nil
+	}
+	if maxPrefixes <= 0 {
+		return nil, nil, fmt.Errorf("cannot summarize %s into %d CIDRs", b, maxPrefixes)
+	}
+	root := newSummaryNode(0, 0, b.ipRange.Intervals(), maxPrefixes)
+	// the fewest CIDRs with the minimal number of extra addresses
+	prefixes := maxPrefixes
+	for prefixes > 1 && root.cost(prefixes-1) == root.cost(maxPrefixes) {
+		prefixes--
+	}
+	summary = NewIPBlock()
+	root.summarize(prefixes, summary.ipRange)
+	return summary, summary.Subtract(b), nil
+}
+
+// summaryNode is a CIDR in the binary trie of all CIDRs, holding a part of the summarized block.
+// costs[k] is the minimal number of extra addresses included by covering this part with at most k CIDRs that are
+// contained in this CIDR, and is math.MaxInt64 if there is no such cover. The costs are computed by dynamic
+// programming over the trie: a CIDR that holds a part of the block is covered either by itself, or by covers of its
+// two halves. Only CIDRs that hold a part of the block, but are not contained in it, have children.
+type summaryNode struct {
+	start       int64
+	size        int64
+	extra       int64 // the number of addresses of the CIDR that are not in the block
+	costs       []int64
+	left, right *summaryNode
+}
+
+// newSummaryNode returns the node of the CIDR with the given start and prefix length, given the intervals of the
+// block that are contained in the CIDR. It computes the costs of at most maxPrefixes CIDRs.
+func newSummaryNode(start int64, length int, spans []interval.Interval, maxPrefixes int) *summaryNode {
+	n := &summaryNode{start: start, size: int64(1) << (maxIPv4Bits - length)}
+	n.extra = n.size
+	for _, span := range spans {
+		n.extra -= span.End() - span.Start() + 1
+	}
+	switch {
+	case len(spans) == 0:
+		n.costs = []int64{0}
+		return n
+	case n.extra == 0:
+		n.costs = []int64{math.MaxInt64, 0}
+		return n
+	}
+	mid := start + n.size/2
+	var leftSpans, rightSpans []interval.Interval
+	for _, span := range spans {
+		if span.Start() < mid {
+			leftSpans = append(leftSpans, interval.New(span.Start(), min(span.End(), mid-1)))
+		}
+		if span.End() >= mid {
+			rightSpans = append(rightSpans, interval.New(max(span.Start(), mid), span.End()))
+		}
+	}
+	n.left = newSummaryNode(start, length+1, leftSpans, maxPrefixes)
+	n.right = newSummaryNode(mid, length+1, rightSpans, maxPrefixes)
+
+	n.costs = make([]int64, min(len(n.left.costs)+len(n.right.costs)-1, maxPrefixes+1))
+	for k := range n.costs {
+		n.costs[k] = math.MaxInt64
+		for i := max(0, k-len(n.right.costs)+1); i <= k && i < len(n.left.costs); i++ {
+			if cost, ok := addCosts(n.left.costs[i], n.right.costs[k-i]); ok && cost < n.costs[k] {
+				n.costs[k] = cost
+			}
+		}
+	}
+	n.costs[1] = min(n.costs[1], n.extra)
+	// at most k CIDRs: a cover with less CIDRs is also a cover with k CIDRs
+	for k := 1; k < len(n.costs); k++ {
+		n.costs[k] = min(n.costs[k], n.costs[k-1])
+	}
+	return n
+}
+
+// addCosts returns the sum of two costs, if both covers exist
+func addCosts(c1, c2 int64) (int64, bool) {
+	if c1 == math.MaxInt64 || c2 == math.MaxInt64 {
+		return 0, false
+	}
+	return c1 + c2, true
+}
+
+// cost returns the minimal number of extra addresses of a cover by at most k CIDRs
+func (n *summaryNode) cost(k int) int64 {
+	return n.costs[min(k, len(n.costs)-1)]
+}
+
+// summarize adds to res the CIDRs of a cover of the node by at most k CIDRs, with the minimal cost
+func (n *summaryNode) summarize(k int, res *interval.CanonicalSet) {
+	target := n.cost(k)
+	switch {
+	case target == 0 && n.left == nil:
+		// the CIDR is either contained in the block, or does not intersect it
+		if n.extra == 0 {
+			res.AddInterval(interval.New(n.start, n.start+n.size-1))
+		}
+		return
+	case n.extra == target:
+		res.AddInterval(interval.New(n.start, n.start+n.size-1))
+		return
+	}
+	for i := 0; i <= k; i++ {
+		if cost, ok := addCosts(n.left.cost(i), n.right.cost(k-i)); ok && cost == target {
+			n.left.summarize(i, res)
+			n.right.summarize(k-i, res)
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"fmt"
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func TestSmallestCoveringCidr(t *testing.T) {
+	for block, expected := range map[string]string{
+		"10.240.10.5-10.240.10.5":    "10.240.10.5",
+		"10.240.10.5-10.240.10.6":    "10.240.10.4/30",
+		"10.240.10.0-10.240.10.255":  "10.240.10.0/24",
+		"10.240.10.200-10.240.11.10": "10.240.10.0/23",
+		"0.0.0.0-255.255.255.255":    "0.0.0.0/0",
+		"1.2.3.4-200.1.1.1":          "0.0.0.0/0",
+	} {
+		b, err := netset.IPBlockFromIPRangeStr(block)
+		require.NoError(t, err)
+		cidr, err := b.SmallestCoveringCidr()
+		require.NoError(t, err)
+		require.Equal(t, expected, cidr.String())
+	}
+	_, err := netset.NewIPBlock().SmallestCoveringCidr()
+	require.Error(t, err)
+}
+
+func TestSummarize(t *testing.T) {
+	b, _ := netset.IPBlockFromCidrList([]string{"10.0.0.0/24", "10.0.1.0/25", "10.0.2.0/24", "10.0.3.1/32", "10.0.8.0/24"})
+
+	summary, extra, err := b.Summarize(10)
+	require.NoError(t, err)
+	require.True(t, summary.Equal(b))
+	require.True(t, extra.IsEmpty())
+
+	summary, extra, err = b.Summarize(4)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.0/23, 10.0.2.0/24, 10.0.3.1/32, 10.0.8.0/24", summary.String())
+	require.Equal(t, "10.0.1.128/25", extra.String())
+
+	// three CIDRs do not include less extra addresses than two
+	summary, extra, err = b.Summarize(3)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.0/22, 10.0.8.0/24", summary.String())
+	require.Equal(t, "10.0.1.128/25, 10.0.3.0/32, 10.0.3.2/31, 10.0.3.4/30, 10.0.3.8/29, 10.0.3.16/28, 10.0.3.32/27, "+
+		"10.0.3.64/26, 10.0.3.128/25", extra.String())
+
+	summary, _, err = b.Summarize(1)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.0/20", summary.String())
+
+	_, _, err = b.Summarize(0)
+	require.Error(t, err)
+	summary, extra, err = netset.NewIPBlock().Summarize(0)
+	require.NoError(t, err)
+	require.True(t, summary.IsEmpty())
+	require.True(t, extra.IsEmpty())
+}
+
+// TestSummarizeOptimal compares Summarize to an exhaustive search, for all the blocks within a /29
+func TestSummarizeOptimal(t *testing.T) {
+	const addresses = 8
+	// the CIDRs within the /29, as bitmasks of its addresses
+	var cidrs []uint
+	for size := 1; size <= addresses; size *= 2 {
+		for start := 0; start < addresses; start += size {
+			cidrs = append(cidrs, (1<<size-1)<<start)
+		}
+	}
+	// optimal[n][block] is the minimal number of extra addresses of a cover of block by at most n CIDRs
+	const maxPrefixes = 3
+	optimal := [maxPrefixes + 1][1 << addresses]int{}
+	for n := range optimal {
+		for block := range optimal[n] {
+			optimal[n][block] = addresses + 1
+		}
+	}
+	for subset := range 1 << len(cidrs) {
+		n := bits.OnesCount(uint(subset))
+		if n > maxPrefixes {
+			continue
+		}
+		cover := uint(0)
+		for i, cidr := range cidrs {
+			if subset&(1<<i) != 0 {
+				cover |= cidr
+			}
+		}
+		for block := range 1 << addresses {
+			if uint(block)&^cover == 0 {
+				for k := n; k <= maxPrefixes; k++ {
+					optimal[k][block] = min(optimal[k][block], bits.OnesCount(cover&^uint(block)))
+				}
+			}
+		}
+	}
+
+	for block := 1; block < 1<<addresses; block++ {
+		b := netset.NewIPBlock()
+		for i := range addresses {
+			if block&(1<<i) != 0 {
+				ip, _ := netset.IPBlockFromIPAddress(fmt.Sprintf("10.0.0.%d", i))
+				b = b.Union(ip)
+			}
+		}
+		for n := 1; n <= maxPrefixes; n++ {
+			summary, extra, err := b.Summarize(n)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(summary.ToCidrList()), n)
+			require.True(t, b.IsSubset(summary))
+			require.True(t, extra.Equal(summary.Subtract(b)))
+			require.Equal(t, optimal[n][block], extra.Size(), b.String())
+		}
+	}
+}
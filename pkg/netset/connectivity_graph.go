@@ -0,0 +1,284 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/interval"
+)
+
+// ConnectivityGraph is a directed graph view of a traffic set: nodes are sets of endpoints, and each edge
+// is labeled with the connections allowed from its source node to its destination node.
+type ConnectivityGraph struct {
+	// Nodes holds the display label of each node
+	Nodes []string
+	// Edges holds the (non-empty) connections between nodes, sorted by source and then by destination
+	Edges []GraphEdge
+}
+
+// GraphEdge is a directed edge of a ConnectivityGraph. Src and Dst are indices into ConnectivityGraph.Nodes
+type GraphEdge struct {
+	Src  int
+	Dst  int
+	Conn *TransportSet
+}
+
+// GraphOptions controls the construction of a ConnectivityGraph
+type GraphOptions struct {
+	// MergeEquivalentNodes merges nodes with identical inbound and outbound connectivity into a single node
+	MergeEquivalentNodes bool
+	// EndpointNames maps endpoint IDs to display names. Relevant only for DiscreteEndpointsTrafficSet;
+	// endpoints without a name are displayed by their ID.
+	EndpointNames map[int64]string
+}
+
+// graphNode is a node under construction: its label, and the index of an atom representing its connectivity
+type graphNode struct {
+	label string
+	atom  int
+}
+
+// Graph returns the connectivity graph of this EndpointsTrafficSet.
+// Unless nodes are merged, each contiguous IP range (a CIDR or a start-end range) is a node of its own.
+func (c *EndpointsTrafficSet) Graph(opts GraphOptions) *ConnectivityGraph {
+	cubes := c.Partitions()
+	atoms := atomize(endpointSets(cubes))
+	slices.SortFunc(atoms, (*IPBlock).Compare)
+	matrix := connectivityMatrix(cubes, atoms)
+
+	var nodes []graphNode
+	if opts.MergeEquivalentNodes {
+		for _, group := range equivalentAtoms(matrix) {
+			block := NewIPBlock()
+			for _, i := range group {
+				block = block.Union(atoms[i])
+			}
+			nodes = append(nodes, graphNode{label: block.String(), atom: group[0]})
+		}
+	} else {
+		type rangeNode struct {
+			block *IPBlock
+			atom  int
+		}
+		var ranges []rangeNode
+		for i, atom := range atoms {
+			for _, block := range atom.Split() {
+				ranges = append(ranges, rangeNode{block: block, atom: i})
+			}
+		}
+		slices.SortFunc(ranges, func(a, b rangeNode) int { return a.block.Compare(b.block) })
+		for _, r := range ranges {
+			nodes = append(nodes, graphNode{label: r.block.ListToPrint()[0], atom: r.atom})
+		}
+	}
+	return newConnectivityGraph(nodes, matrix)
+}
+
+// Graph returns the connectivity graph of this DiscreteEndpointsTrafficSet.
+// Unless nodes are merged, each contiguous range of endpoint IDs is a node of its own.
+func (c *DiscreteEndpointsTrafficSet) Graph(opts GraphOptions) *ConnectivityGraph {
+	cubes := c.Partitions()
+	atoms := atomize(endpointSets(cubes))
+	slices.SortFunc(atoms, func(a, b *interval.CanonicalSet) int { return cmp.Compare(a.Min(), b.Min()) })
+	matrix := connectivityMatrix(cubes, atoms)
+
+	label := endpointsLabeler(opts.EndpointNames)
+	var nodes []graphNode
+	if opts.MergeEquivalentNodes {
+		for _, group := range equivalentAtoms(matrix) {
+			set := interval.NewCanonicalSet()
+			for _, i := range group {
+				set = set.Union(atoms[i])
+			}
+			nodes = append(nodes, graphNode{label: label(set), atom: group[0]})
+		}
+	} else {
+		type rangeNode struct {
+			span interval.Interval
+			atom int
+		}
+		var ranges []rangeNode
+		for i, atom := range atoms {
+			for _, span := range atom.Intervals() {
+				ranges = append(ranges, rangeNode{span: span, atom: i})
+			}
+		}
+		slices.SortFunc(ranges, func(a, b rangeNode) int { return cmp.Compare(a.span.Start(), b.span.Start()) })
+		for _, r := range ranges {
+			nodes = append(nodes, graphNode{label: label(r.span.ToSet()), atom: r.atom})
+		}
+	}
+	return newConnectivityGraph(nodes, matrix)
+}
+
+// endpointsLabeler returns a function that labels sets of endpoints with the given names (see endpointLabels)
+func endpointsLabeler(names map[int64]string) func(*interval.CanonicalSet) string {
+	if len(names) == 0 {
+		return (*interval.CanonicalSet).String
+	}
+	ids := make([]int64, 0, len(names))
+	for id := range names {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	named := interval.NewCanonicalSet()
+	for _, id := range ids {
+		named.AddInterval(interval.New(id, id))
+	}
+	return func(set *interval.CanonicalSet) string {
+		return strings.Join(endpointLabels(set, named, func(id int64) string { return names[id] }), commaSeparator)
+	}
+}
+
+// endpointSets returns the src and dst sets of all the given cubes
+func endpointSets[S ds.Set[S]](cubes []ds.Triple[S, S, *TransportSet]) []S {
+	res := make([]S, 0, 2*len(cubes))
+	for _, cube := range cubes {
+		res = append(res, cube.S1, cube.S2)
+	}
+	return res
+}
+
+// atomize returns disjoint non-empty sets whose union equals the union of the input sets,
+// such that every input set is a union of some of the returned sets
+func atomize[S ds.Set[S]](sets []S) []S {
+	var atoms []S
+	for _, s := range sets {
+		var next []S
+		for _, atom := range atoms {
+			common := atom.Intersect(s)
+			if common.IsEmpty() {
+				next = append(next, atom)
+				continue
+			}
+			next = append(next, common)
+			if rest := atom.Subtract(common); !rest.IsEmpty() {
+				next = append(next, rest)
+			}
+			s = s.Subtract(common)
+		}
+		if !s.IsEmpty() {
+			next = append(next, s)
+		}
+		atoms = next
+	}
+	return atoms
+}
+
+// connectivityMatrix returns a matrix m such that m[i][j] holds the connections allowed from atoms[i] to atoms[j]
+func connectivityMatrix[S ds.Set[S]](cubes []ds.Triple[S, S, *TransportSet], atoms []S) [][]*TransportSet {
+	matrix := make([][]*TransportSet, len(atoms))
+	for i := range matrix {
+		matrix[i] = make([]*TransportSet, len(atoms))
+		for j := range matrix[i] {
+			matrix[i][j] = NoTransports()
+		}
+	}
+	for _, cube := range cubes {
+		srcAtoms := coveredAtoms(atoms, cube.S1)
+		dstAtoms := coveredAtoms(atoms, cube.S2)
+		for _, i := range srcAtoms {
+			for _, j := range dstAtoms {
+				matrix[i][j] = matrix[i][j].Union(cube.S3)
+			}
+		}
+	}
+	return matrix
+}
+
+// coveredAtoms returns the indices of the atoms contained in s
+func coveredAtoms[S ds.Set[S]](atoms []S, s S) []int {
+	var res []int
+	for i, atom := range atoms {
+		if atom.IsSubset(s) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// equivalentAtoms groups the indices of atoms with identical rows and columns in the connectivity matrix
+func equivalentAtoms(matrix [][]*TransportSet) [][]int {
+	var groups [][]int
+	for i := range matrix {
+		found := false
+		for g, group := range groups {
+			if sameConnectivity(matrix, i, group[0]) {
+				groups[g] = append(groups[g], i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, []int{i})
+		}
+	}
+	return groups
+}
+
+func sameConnectivity(matrix [][]*TransportSet, i, j int) bool {
+	for k := range matrix {
+		if !matrix[i][k].Equal(matrix[j][k]) || !matrix[k][i].Equal(matrix[k][j]) {
+			return false
+		}
+	}
+	return true
+}
+
+func newConnectivityGraph(nodes []graphNode, matrix [][]*TransportSet) *ConnectivityGraph {
+	res := &ConnectivityGraph{Nodes: make([]string, len(nodes))}
+	for i, src := range nodes {
+		res.Nodes[i] = src.label
+		for j, dst := range nodes {
+			if conn := matrix[src.atom][dst.atom]; !conn.IsEmpty() {
+				res.Edges = append(res.Edges, GraphEdge{Src: i, Dst: j, Conn: conn.Copy()})
+			}
+		}
+	}
+	return res
+}
+
+func graphNodeID(i int) string {
+	return fmt.Sprintf("n%d", i)
+}
+
+// DOT returns a Graphviz DOT representation of the graph
+func (g *ConnectivityGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph {\n")
+	for i, label := range g.Nodes {
+		fmt.Fprintf(&sb, "\t%s [label=%q]\n", graphNodeID(i), label)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "\t%s -> %s [label=%q]\n", graphNodeID(e.Src), graphNodeID(e.Dst), e.Conn.String())
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// mermaidLabel returns a quoted Mermaid label, escaping the characters Mermaid does not accept inside quotes
+func mermaidLabel(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// Mermaid returns a Mermaid flowchart representation of the graph
+func (g *ConnectivityGraph) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	for i, label := range g.Nodes {
+		fmt.Fprintf(&sb, "\t%s[%s]\n", graphNodeID(i), mermaidLabel(label))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "\t%s -->|%s| %s\n", graphNodeID(e.Src), mermaidLabel(e.Conn.String()), graphNodeID(e.Dst))
+	}
+	return sb.String()
+}
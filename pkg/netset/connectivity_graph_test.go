@@ -0,0 +1,84 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/interval"
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func TestEndpointsTrafficSetGraph(t *testing.T) {
+	cidr1, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	cidr2, _ := netset.IPBlockFromCidr("10.240.20.0/24")
+	cidr3, _ := netset.IPBlockFromCidr("10.240.30.0/24")
+	tcp22 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22)
+
+	conns := netset.NewEndpointsTrafficSet(cidr1, cidr3, tcp22).Union(
+		netset.NewEndpointsTrafficSet(cidr2, cidr3, tcp22))
+
+	graph := conns.Graph(netset.GraphOptions{})
+	require.Equal(t, []string{"10.240.10.0/24", "10.240.20.0/24", "10.240.30.0/24"}, graph.Nodes)
+	require.Len(t, graph.Edges, 2)
+	fmt.Println(graph.DOT())
+	require.Equal(t, "digraph {\n"+
+		"\tn0 [label=\"10.240.10.0/24\"]\n"+
+		"\tn1 [label=\"10.240.20.0/24\"]\n"+
+		"\tn2 [label=\"10.240.30.0/24\"]\n"+
+		"\tn0 -> n2 [label=\"TCP dst-ports: 22\"]\n"+
+		"\tn1 -> n2 [label=\"TCP dst-ports: 22\"]\n"+
+		"}\n", graph.DOT())
+
+	merged := conns.Graph(netset.GraphOptions{MergeEquivalentNodes: true})
+	require.Equal(t, []string{"10.240.10.0/24, 10.240.20.0/24", "10.240.30.0/24"}, merged.Nodes)
+	fmt.Println(merged.Mermaid())
+	require.Equal(t, "graph LR\n"+
+		"\tn0[\"10.240.10.0/24, 10.240.20.0/24\"]\n"+
+		"\tn1[\"10.240.30.0/24\"]\n"+
+		"\tn0 -->|\"TCP dst-ports: 22\"| n1\n", merged.Mermaid())
+}
+
+func TestEndpointsTrafficSetGraphOverlappingBlocks(t *testing.T) {
+	cidr1, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	cidr2, _ := netset.IPBlockFromCidr("10.240.10.0/25")
+
+	conns := netset.NewEndpointsTrafficSet(cidr1, cidr1, netset.AllTCPTransport()).Union(
+		netset.NewEndpointsTrafficSet(cidr2, cidr2, netset.AllUDPTransport()))
+
+	graph := conns.Graph(netset.GraphOptions{})
+	require.Equal(t, []string{"10.240.10.0/25", "10.240.10.128/25"}, graph.Nodes)
+	require.Len(t, graph.Edges, 4)
+	require.Equal(t, "TCP,UDP", graph.Edges[0].Conn.String())
+	for _, e := range graph.Edges[1:] {
+		require.Equal(t, "TCP", e.Conn.String())
+	}
+}
+
+func TestDiscreteEndpointsTrafficSetGraph(t *testing.T) {
+	conns := netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 1).ToSet(), interval.New(2, 2).ToSet(), netset.AllICMPTransport())
+	names := map[int64]string{0: "vsi-a", 1: "vsi-b", 2: "vsi-c"}
+
+	graph := conns.Graph(netset.GraphOptions{EndpointNames: names})
+	require.Equal(t, []string{"vsi-a, vsi-b", "vsi-c"}, graph.Nodes)
+	require.Equal(t, []netset.GraphEdge{{Src: 0, Dst: 1, Conn: netset.AllICMPTransport()}}, graph.Edges)
+
+	// one node per range of IDs, rather than per ID
+	huge := netset.NewDiscreteEndpointsTrafficSet(interval.New(0, netset.MaxEndpointID).ToSet(), interval.New(2, 2).ToSet(),
+		netset.AllICMPTransport())
+	require.Equal(t, []string{"0-1", "2", "3-4294967295"}, huge.Graph(netset.GraphOptions{}).Nodes)
+
+	merged := conns.Graph(netset.GraphOptions{MergeEquivalentNodes: true})
+	require.Equal(t, []string{"0-1", "2"}, merged.Nodes)
+	merged = conns.Graph(netset.GraphOptions{MergeEquivalentNodes: true, EndpointNames: names})
+	require.Equal(t, []string{"vsi-a, vsi-b", "vsi-c"}, merged.Nodes)
+	require.Len(t, merged.Edges, 1)
+}
@@ -7,7 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package netset
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"sort"
 	"strings"
 
@@ -18,8 +21,10 @@ import (
 // DiscreteEndpointsTrafficSet captures a set of traffic attributes for tuples of (source endpoints, desination endpoints, TransportSet),
 // where TransportSet is a set of TCP/UPD/ICMP with their properties (src,dst ports / icmp type,code)
 // and source/destination endpoints are from a discrete set represented by integer IDs (could be mapped to VMs UIDs / Pod UIDs, etc.. )
+// A set may be attached to an EndpointRegistry (see WithRegistry), to render its endpoints by their names.
 type DiscreteEndpointsTrafficSet struct {
-	props ds.TripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *TransportSet]
+	props    ds.TripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *TransportSet]
+	registry *EndpointRegistry
 }
 
 // EmptyDiscreteEndpointsTrafficSet returns an empty DiscreteEndpointsTrafficSet
@@ -27,6 +32,28 @@ func EmptyDiscreteEndpointsTrafficSet() *DiscreteEndpointsTrafficSet {
 	return &DiscreteEndpointsTrafficSet{props: ds.NewLeftTripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *TransportSet]()}
 }
 
+// WithRegistry returns a copy of this set, attached to the given registry: its String, MarshalJSON and Cubes render
+// endpoints by their names in the registry. Sets derived from it, e.g., by Union or Compose, are attached to it as well.
+// The registry does not affect the content of the set, so it is ignored by Equal, Compare and Hash.
+func (c *DiscreteEndpointsTrafficSet) WithRegistry(r *EndpointRegistry) *DiscreteEndpointsTrafficSet {
+	return &DiscreteEndpointsTrafficSet{props: c.props.Copy(), registry: r}
+}
+
+// Registry returns the registry this set is attached to, or nil
+func (c *DiscreteEndpointsTrafficSet) Registry() *EndpointRegistry {
+	return c.registry
+}
+
+// derive returns a set with the given connections, attached to the registry of c, or else of other
+func (c *DiscreteEndpointsTrafficSet) derive(props ds.TripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *TransportSet],
+	other *DiscreteEndpointsTrafficSet) *DiscreteEndpointsTrafficSet {
+	registry := c.registry
+	if registry == nil {
+		registry = other.registry
+	}
+	return &DiscreteEndpointsTrafficSet{props: props, registry: registry}
+}
+
 // Equal returns true is this DiscreteEndpointsTrafficSet captures the exact same set of connections as `other` does.
 func (c *DiscreteEndpointsTrafficSet) Equal(other *DiscreteEndpointsTrafficSet) bool {
 	return c.props.Equal(other.props)
@@ -35,14 +62,26 @@ func (c *DiscreteEndpointsTrafficSet) Equal(other *DiscreteEndpointsTrafficSet)
 // Copy returns new DiscreteEndpointsTrafficSet object with same set of connections as current one
 func (c *DiscreteEndpointsTrafficSet) Copy() *DiscreteEndpointsTrafficSet {
 	return &DiscreteEndpointsTrafficSet{
-		props: c.props.Copy(),
+		props:    c.props.Copy(),
+		registry: c.registry,
 	}
 }
 
 // Intersect returns a DiscreteEndpointsTrafficSet object with connection tuples that result from intersection of
 // this and `other` sets
 func (c *DiscreteEndpointsTrafficSet) Intersect(other *DiscreteEndpointsTrafficSet) *DiscreteEndpointsTrafficSet {
-	return &DiscreteEndpointsTrafficSet{props: c.props.Intersect(other.props)}
+	return c.derive(c.props.Intersect(other.props), other)
+}
+
+// Compare returns -1 if c<other, 1 if c>other, 0 o.w.
+// Sets are ordered lexicographically by their sorted cubes (see Partitions).
+func (c *DiscreteEndpointsTrafficSet) Compare(other *DiscreteEndpointsTrafficSet) int {
+	return c.props.Compare(other.props)
+}
+
+// Hash returns the hash value of this DiscreteEndpointsTrafficSet
+func (c *DiscreteEndpointsTrafficSet) Hash() int {
+	return c.props.Hash()
 }
 
 // IsEmpty returns true of the DiscreteEndpointsTrafficSet is empty
@@ -50,27 +89,75 @@ func (c *DiscreteEndpointsTrafficSet) IsEmpty() bool {
 	return c.props.IsEmpty()
 }
 
+// Size returns the number of concrete connections in this DiscreteEndpointsTrafficSet
+func (c *DiscreteEndpointsTrafficSet) Size() int {
+	return c.props.Size()
+}
+
+// BigSize returns the exact number of concrete connections in this DiscreteEndpointsTrafficSet
+func (c *DiscreteEndpointsTrafficSet) BigSize() *big.Int {
+	return c.props.BigSize()
+}
+
 // Union returns a DiscreteEndpointsTrafficSet object with connection tuples that result from union of
 // this and `other` sets
 func (c *DiscreteEndpointsTrafficSet) Union(other *DiscreteEndpointsTrafficSet) *DiscreteEndpointsTrafficSet {
 	if other.IsEmpty() {
-		return c.Copy()
+		return c.derive(c.props.Copy(), other)
 	}
 	if c.IsEmpty() {
-		return other.Copy()
-	}
-	return &DiscreteEndpointsTrafficSet{
-		props: c.props.Union(other.props),
+		return c.derive(other.props.Copy(), other)
 	}
+	return c.derive(c.props.Union(other.props), other)
 }
 
 // Subtract returns a DiscreteEndpointsTrafficSet object with connection tuples that result from subtraction of
 // `other` from this set
 func (c *DiscreteEndpointsTrafficSet) Subtract(other *DiscreteEndpointsTrafficSet) *DiscreteEndpointsTrafficSet {
 	if other.IsEmpty() {
-		return c.Copy()
+		return c.derive(c.props.Copy(), other)
 	}
-	return &DiscreteEndpointsTrafficSet{props: c.props.Subtract(other.props)}
+	return c.derive(c.props.Subtract(other.props), other)
+}
+
+// MaxEndpointID is the maximal endpoint ID in a DiscreteEndpointsTrafficSet universe. Since the universe is huge,
+// sets derived from it (e.g., by Complement) should be traversed by their ranges of IDs rather than by their elements.
+// For the complement within the endpoints actually in use, e.g., those of an EndpointRegistry, see ComplementIn.
+const MaxEndpointID = math.MaxUint32
+
+// AllEndpoints returns the set of all valid endpoint IDs: [0, MaxEndpointID]
+func AllEndpoints() *interval.CanonicalSet {
+	return interval.New(0, MaxEndpointID).ToSet()
+}
+
+// Universe returns the set of all connections between any endpoints.
+// If the transports of this set are strict (see TransportSet.Strict), so are those of its universe.
+func (c *DiscreteEndpointsTrafficSet) Universe() *DiscreteEndpointsTrafficSet {
+	return c.derive(ds.CartesianLeftTriple(AllEndpoints(), AllEndpoints(), c.allTransports()), c)
+}
+
+// allTransports returns all the transports, which are strict if the transports of c are strict (see strictTransports)
+func (c *DiscreteEndpointsTrafficSet) allTransports() *TransportSet {
+	if strictTransports(c.Partitions()) {
+		return AllTransportsStrict()
+	}
+	return AllTransports()
+}
+
+// Complement returns the connections not in this set
+func (c *DiscreteEndpointsTrafficSet) Complement() *DiscreteEndpointsTrafficSet {
+	return c.Universe().Subtract(c)
+}
+
+// ComplementIn returns the connections between the given endpoints that are not in this set, e.g., the connections
+// between registered endpoints that are not in this set: c.ComplementIn(r.All())
+func (c *DiscreteEndpointsTrafficSet) ComplementIn(endpoints *interval.CanonicalSet) *DiscreteEndpointsTrafficSet {
+	return c.derive(ds.CartesianLeftTriple(endpoints, endpoints, c.allTransports()), c).Subtract(c)
+}
+
+// IsAll returns true if this set holds all connections between any endpoints
+func (c *DiscreteEndpointsTrafficSet) IsAll() bool {
+	return c.Equal(c.Universe())
 }
 
 // IsSubset returns true if c is subset of other
@@ -84,19 +171,97 @@ func NewDiscreteEndpointsTrafficSet(src, dst *interval.CanonicalSet, conn *Trans
 	return &DiscreteEndpointsTrafficSet{props: ds.CartesianLeftTriple(src, dst, conn)}
 }
 
+// Partitions returns the cubes of this set, sorted by ds.CompareTriples
 func (c *DiscreteEndpointsTrafficSet) Partitions() []ds.Triple[*interval.CanonicalSet, *interval.CanonicalSet, *TransportSet] {
 	return c.props.Partitions()
 }
 
+// String returns a string representation of this set: its cubes, with endpoints rendered by their names if the set is
+// attached to a registry (see WithRegistry), and by their IDs otherwise.
 func (c *DiscreteEndpointsTrafficSet) String() string {
 	if c.IsEmpty() {
 		return "<empty>"
 	}
 	cubes := c.Partitions()
 	var resStrings = make([]string, len(cubes))
-	for i, c := range cubes {
-		resStrings[i] = fmt.Sprintf("src: %s, dst: %s, conns: %s", c.S1.String(), c.S2.String(), c.S3.String())
+	for i, cube := range cubes {
+		resStrings[i] = fmt.Sprintf("src: %s, dst: %s, conns: %s", c.endpointsStr(cube.S1), c.endpointsStr(cube.S2), cube.S3.String())
 	}
 	sort.Strings(resStrings)
 	return strings.Join(resStrings, comma)
 }
+
+func (c *DiscreteEndpointsTrafficSet) endpointsStr(set *interval.CanonicalSet) string {
+	if c.registry == nil {
+		return set.String()
+	}
+	return "{" + strings.Join(c.registry.Names(set), commaSeparator) + "}"
+}
+
+// endpointsNames returns the labels of the endpoints in set: their names if the set is attached to a registry,
+// and their ranges of IDs otherwise
+func (c *DiscreteEndpointsTrafficSet) endpointsNames(set *interval.CanonicalSet) []string {
+	if c.registry == nil {
+		return endpointLabels(set, interval.NewCanonicalSet(), nil)
+	}
+	return c.registry.Names(set)
+}
+
+// NamedCube is a single cube of a DiscreteEndpointsTrafficSet, with endpoints rendered by their names
+// (see EndpointRegistry.Names), or by their ranges of IDs if the set is not attached to a registry
+type NamedCube struct {
+	Src  []string `json:"src"`
+	Dst  []string `json:"dst"`
+	Conn Details  `json:"conn"`
+}
+
+// Cubes returns the cubes of this set, sorted by ds.CompareTriples
+func (c *DiscreteEndpointsTrafficSet) Cubes() []NamedCube {
+	cubes := c.Partitions()
+	res := make([]NamedCube, len(cubes))
+	for i, cube := range cubes {
+		res[i] = NamedCube{Src: c.endpointsNames(cube.S1), Dst: c.endpointsNames(cube.S2), Conn: ToJSON(cube.S3)}
+	}
+	return res
+}
+
+// MarshalJSON returns a JSON representation of this set: the list of its cubes (see Cubes)
+func (c *DiscreteEndpointsTrafficSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Cubes())
+}
+
+// Compose returns the connections from sources of this set, through an intermediate endpoint, to destinations of
+// `other`: (src, dst, conn) such that for some endpoint x, (src, x, conn) is in this set and (x, dst, conn) is in `other`
+func (c *DiscreteEndpointsTrafficSet) Compose(other *DiscreteEndpointsTrafficSet) *DiscreteEndpointsTrafficSet {
+	return c.derive(ds.ComposeTriples(c.props, other.props), other)
+}
+
+// Reachable returns the connections that can be made in at most k hops, where every hop is a connection
+// in this set, and all the hops of a path use the same transport.
+func (c *DiscreteEndpointsTrafficSet) Reachable(k int) *DiscreteEndpointsTrafficSet {
+	if k < 1 {
+		return c.derive(ds.NewLeftTripleSet[*interval.CanonicalSet, *interval.CanonicalSet, *TransportSet](), c)
+	}
+	res, path := c.Copy(), c
+	for i := 1; i < k; i++ {
+		path = path.Compose(c)
+		next := res.Union(path)
+		if next.Equal(res) {
+			break
+		}
+		res = next
+	}
+	return res
+}
+
+// TransitiveClosure returns the connections that can be made in any number of hops (see Reachable)
+func (c *DiscreteEndpointsTrafficSet) TransitiveClosure() *DiscreteEndpointsTrafficSet {
+	res := c.Copy()
+	for {
+		next := res.Union(res.Compose(c))
+		if next.Equal(res) {
+			return res
+		}
+		res = next
+	}
+}
@@ -37,3 +37,30 @@ func TestDiscreteTrafficSetBasicOperations(t *testing.T) {
 
 	fmt.Println("done")
 }
+
+func TestDiscreteTrafficSetReachability(t *testing.T) {
+	// a chain 0 -> 1 -> 2 -> 3 over TCP, where only 0 -> 1 also allows UDP
+	tcp := netset.AllTCPTransport()
+	chain := netset.EmptyDiscreteEndpointsTrafficSet()
+	for i := int64(0); i < 3; i++ {
+		chain = chain.Union(netset.NewDiscreteEndpointsTrafficSet(interval.New(i, i).ToSet(), interval.New(i+1, i+1).ToSet(), tcp))
+	}
+	chain = chain.Union(netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 0).ToSet(), interval.New(1, 1).ToSet(),
+		netset.AllUDPTransport()))
+
+	twoHops := chain.Compose(chain)
+	require.True(t, twoHops.Equal(netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 1).ToSet(), interval.New(2, 3).ToSet(), tcp).Subtract(
+		netset.NewDiscreteEndpointsTrafficSet(interval.New(1, 1).ToSet(), interval.New(2, 2).ToSet(), tcp)).Subtract(
+		netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 0).ToSet(), interval.New(3, 3).ToSet(), tcp))))
+
+	require.True(t, chain.Reachable(0).IsEmpty())
+	require.True(t, chain.Reachable(1).Equal(chain))
+	require.True(t, chain.Reachable(2).Equal(chain.Union(twoHops)))
+
+	closure := chain.TransitiveClosure()
+	require.True(t, closure.Equal(chain.Reachable(3)))
+	require.True(t, closure.Equal(chain.Reachable(10)))
+	require.True(t, netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 0).ToSet(), interval.New(3, 3).ToSet(), tcp).IsSubset(closure))
+	require.False(t, netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 0).ToSet(), interval.New(2, 2).ToSet(),
+		netset.AllUDPTransport()).IsSubset(closure))
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/np-guard/models/pkg/interval"
+)
+
+// EndpointRegistry assigns stable integer IDs to endpoint names (e.g., VM UIDs or pod names), to be used in
+// DiscreteEndpointsTrafficSet objects. IDs are assigned sequentially, starting from 0, in registration order.
+// The registry also holds named groups of endpoints.
+type EndpointRegistry struct {
+	ids    map[string]int64
+	names  []string
+	groups map[string]*interval.CanonicalSet
+}
+
+// NewEndpointRegistry returns an empty EndpointRegistry
+func NewEndpointRegistry() *EndpointRegistry {
+	return &EndpointRegistry{ids: map[string]int64{}, groups: map[string]*interval.CanonicalSet{}}
+}
+
+// Register assigns IDs to the given names, and returns the set of their IDs.
+// Names that are already registered keep their IDs.
+func (r *EndpointRegistry) Register(names ...string) *interval.CanonicalSet {
+	res := interval.NewCanonicalSet()
+	for _, name := range names {
+		id, ok := r.ids[name]
+		if !ok {
+			id = int64(len(r.names))
+			r.ids[name] = id
+			r.names = append(r.names, name)
+		}
+		res.AddInterval(interval.New(id, id))
+	}
+	return res
+}
+
+// Size returns the number of registered endpoints
+func (r *EndpointRegistry) Size() int {
+	return len(r.names)
+}
+
+// ID returns the ID of the given name, and whether it is registered
+func (r *EndpointRegistry) ID(name string) (int64, bool) {
+	id, ok := r.ids[name]
+	return id, ok
+}
+
+// Name returns the name of the given ID, and whether it is registered
+func (r *EndpointRegistry) Name(id int64) (string, bool) {
+	if id < 0 || id >= int64(len(r.names)) {
+		return "", false
+	}
+	return r.names[id], true
+}
+
+// All returns the set of IDs of all registered endpoints
+func (r *EndpointRegistry) All() *interval.CanonicalSet {
+	if len(r.names) == 0 {
+		return interval.NewCanonicalSet()
+	}
+	return interval.New(0, int64(len(r.names))-1).ToSet()
+}
+
+// Set returns the set of IDs of the given names. It returns an error if some name is not registered.
+func (r *EndpointRegistry) Set(names ...string) (*interval.CanonicalSet, error) {
+	res := interval.NewCanonicalSet()
+	for _, name := range names {
+		id, ok := r.ids[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown endpoint %q", name)
+		}
+		res.AddInterval(interval.New(id, id))
+	}
+	return res, nil
+}
+
+// Match returns the set of IDs of all registered names matching the given glob pattern (see path.Match)
+func (r *EndpointRegistry) Match(pattern string) (*interval.CanonicalSet, error) {
+	res := interval.NewCanonicalSet()
+	for id, name := range r.names {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			res.AddInterval(interval.New(int64(id), int64(id)))
+		}
+	}
+	return res, nil
+}
+
+// AddGroup defines a named group of endpoints, replacing a previous group with the same name (if any).
+// It returns an error if some member is not registered.
+func (r *EndpointRegistry) AddGroup(group string, members ...string) error {
+	set, err := r.Set(members...)
+	if err != nil {
+		return fmt.Errorf("group %q: %w", group, err)
+	}
+	r.groups[group] = set
+	return nil
+}
+
+// Group returns the set of IDs of the given group members, and whether the group exists
+func (r *EndpointRegistry) Group(group string) (*interval.CanonicalSet, bool) {
+	set, ok := r.groups[group]
+	if !ok {
+		return nil, false
+	}
+	return set.Copy(), true
+}
+
+// Names returns the names of the IDs in the given set, ordered by ID. Ranges of unregistered IDs are rendered
+// by their bounds, e.g., "7-9", so the result is never longer than the registry plus the number of ranges.
+func (r *EndpointRegistry) Names(set *interval.CanonicalSet) []string {
+	return endpointLabels(set, r.All(), func(id int64) string { return r.names[id] })
+}
+
+// endpointLabels returns labels of the IDs in set, ordered by ID: IDs in named are labeled by name, and every
+// range of other IDs is labeled by its bounds (see interval.Interval.ShortString).
+// Only the named IDs are enumerated.
+func endpointLabels(set, named *interval.CanonicalSet, name func(int64) string) []string {
+	res := []string{}
+	namedSpans := set.Intersect(named).Intervals()
+	otherSpans := set.Subtract(named).Intervals()
+	for len(namedSpans) > 0 || len(otherSpans) > 0 {
+		if len(namedSpans) == 0 || len(otherSpans) > 0 && otherSpans[0].Start() < namedSpans[0].Start() {
+			res = append(res, otherSpans[0].ShortString())
+			otherSpans = otherSpans[1:]
+			continue
+		}
+		for id := namedSpans[0].Start(); id <= namedSpans[0].End(); id++ {
+			res = append(res, name(id))
+		}
+		namedSpans = namedSpans[1:]
+	}
+	return res
+}
+
+// NameMap returns a mapping from the IDs of all registered endpoints to their names, e.g., for GraphOptions
+func (r *EndpointRegistry) NameMap() map[int64]string {
+	res := make(map[int64]string, len(r.names))
+	for id, name := range r.names {
+		res[int64(id)] = name
+	}
+	return res
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/interval"
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func TestEndpointRegistry(t *testing.T) {
+	r := netset.NewEndpointRegistry()
+	require.True(t, r.Register("web-1", "web-2", "db-1").Equal(interval.New(0, 2).ToSet()))
+	require.True(t, r.Register("db-1", "cache-1").Equal(interval.New(2, 3).ToSet()))
+	require.Equal(t, 4, r.Size())
+
+	id, ok := r.ID("cache-1")
+	require.True(t, ok)
+	require.Equal(t, int64(3), id)
+	name, ok := r.Name(1)
+	require.True(t, ok)
+	require.Equal(t, "web-2", name)
+	_, ok = r.Name(4)
+	require.False(t, ok)
+
+	webs, err := r.Match("web-*")
+	require.Nil(t, err)
+	require.True(t, webs.Equal(interval.New(0, 1).ToSet()))
+	_, err = r.Match("[")
+	require.NotNil(t, err)
+
+	_, err = r.Set("web-1", "web-3")
+	require.NotNil(t, err)
+	require.NotNil(t, r.AddGroup("backend", "db-1", "db-2"))
+	require.Nil(t, r.AddGroup("backend", "db-1", "cache-1"))
+	backend, ok := r.Group("backend")
+	require.True(t, ok)
+	require.True(t, backend.Equal(interval.New(2, 3).ToSet()))
+	_, ok = r.Group("frontend")
+	require.False(t, ok)
+
+	require.Equal(t, []string{"web-1", "cache-1", "7"}, r.Names(interval.New(0, 0).ToSet().Union(interval.New(3, 3).ToSet()).Union(
+		interval.New(7, 7).ToSet())))
+	// unregistered IDs are rendered by ranges
+	require.Equal(t, []string{"db-1", "cache-1", "4-4294967295"}, r.Names(interval.New(2, netset.MaxEndpointID).ToSet()))
+}
+
+func TestDiscreteEndpointsTrafficSetNamed(t *testing.T) {
+	r := netset.NewEndpointRegistry()
+	webs := r.Register("web-1", "web-2")
+	dbs := r.Register("db-1")
+	tcp := netset.NewDiscreteEndpointsTrafficSet(webs, dbs, netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 5432, 5432))
+	require.Equal(t, "src: 0-1, dst: 2, conns: TCP dst-ports: 5432", tcp.String())
+	require.Nil(t, tcp.Registry())
+
+	// the registry is attached to sets derived from an attached set
+	conns := tcp.WithRegistry(r).Union(netset.NewDiscreteEndpointsTrafficSet(dbs, webs, netset.AllICMPTransport()))
+	require.Same(t, r, conns.Registry())
+	require.True(t, conns.Equal(conns.WithRegistry(nil)))
+	require.Equal(t, "src: {db-1}, dst: {web-1, web-2}, conns: ICMP,"+
+		"src: {web-1, web-2}, dst: {db-1}, conns: TCP dst-ports: 5432", conns.String())
+	require.Equal(t, "<empty>", conns.Subtract(conns).String())
+	require.Equal(t, "src: {web-1, web-2, 3-4294967295}, dst: {db-1}, conns: TCP dst-ports: 5432",
+		netset.NewDiscreteEndpointsTrafficSet(netset.AllEndpoints().Subtract(dbs), dbs,
+			netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 5432, 5432)).Intersect(conns.Universe()).String())
+
+	cubes := conns.Cubes()
+	require.Len(t, cubes, 2)
+	require.Equal(t, []string{"web-1", "web-2"}, cubes[0].Src)
+	require.Equal(t, []string{"0-1"}, tcp.Cubes()[0].Src)
+
+	js, err := json.Marshal(conns)
+	require.Nil(t, err)
+	require.JSONEq(t, `[
+		{"src": ["web-1", "web-2"], "dst": ["db-1"],
+		 "conn": [{"protocol": "TCP", "min_destination_port": 5432, "max_destination_port": 5432}]},
+		{"src": ["db-1"], "dst": ["web-1", "web-2"], "conn": [{"protocol": "ICMP"}]}
+	]`, string(js))
+
+	graph := conns.Graph(netset.GraphOptions{EndpointNames: r.NameMap()})
+	require.Equal(t, []string{"web-1, web-2", "db-1"}, graph.Nodes)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/interval"
+)
+
+// EndpointsClass is a set of IP addresses with identical connectivity:
+// every member may connect exactly to the same destinations over the same transports,
+// and may be connected to exactly from the same sources over the same transports.
+type EndpointsClass struct {
+	Members *IPBlock
+	// Outbound holds the connections from the members
+	Outbound *EndpointsTrafficSet
+	// Inbound holds the connections to the members
+	Inbound *EndpointsTrafficSet
+}
+
+// DiscreteEndpointsClass is a set of endpoints with identical connectivity (see EndpointsClass)
+type DiscreteEndpointsClass struct {
+	Members *interval.CanonicalSet
+	// Outbound holds the connections from the members
+	Outbound *DiscreteEndpointsTrafficSet
+	// Inbound holds the connections to the members
+	Inbound *DiscreteEndpointsTrafficSet
+}
+
+// EquivalenceClasses partitions all the IP addresses into classes of addresses with identical connectivity.
+// The addresses not mentioned in this set (as sources or destinations), if any, form a class with no connections.
+// The classes are ordered by their members.
+func (c *EndpointsTrafficSet) EquivalenceClasses() []*EndpointsClass {
+	members := equivalenceClasses(c.props, NewIPBlock().Universe())
+	slices.SortFunc(members, (*IPBlock).Compare)
+	res := make([]*EndpointsClass, len(members))
+	for i, m := range members {
+		res[i] = &EndpointsClass{
+			Members:  m,
+			Outbound: c.restrict(m, true),
+			Inbound:  c.restrict(m, false),
+		}
+	}
+	return res
+}
+
+// restrict returns the connections of c from the given addresses (if src is true) or to them (otherwise),
+// in the backend of c
+func (c *EndpointsTrafficSet) restrict(members *IPBlock, src bool) *EndpointsTrafficSet {
+	return newTrafficSet(withBackend(restrictEndpoints(c.props, members, src), c.Backend(), c.strict), c.strict)
+}
+
+// EquivalenceClasses partitions all the endpoints (see AllEndpoints) into classes of endpoints with identical
+// connectivity. The endpoints not mentioned in this set (as sources or destinations), if any, form a class with no
+// connections. The classes are ordered by their members, and their sets are attached to the registry of this set.
+func (c *DiscreteEndpointsTrafficSet) EquivalenceClasses() []*DiscreteEndpointsClass {
+	members := equivalenceClasses(c.props, AllEndpoints())
+	slices.SortFunc(members, func(a, b *interval.CanonicalSet) int { return cmp.Compare(a.Min(), b.Min()) })
+	res := make([]*DiscreteEndpointsClass, len(members))
+	for i, m := range members {
+		res[i] = &DiscreteEndpointsClass{
+			Members:  m,
+			Outbound: c.derive(restrictEndpoints(c.props, m, true), c),
+			Inbound:  c.derive(restrictEndpoints(c.props, m, false), c),
+		}
+	}
+	return res
+}
+
+// equivalenceClasses returns the common refinement of the grouping of sources by their outbound connectivity,
+// and the grouping of destinations by their inbound connectivity, and the endpoints of universe in neither grouping
+func equivalenceClasses[S ds.Set[S]](t ds.TripleSet[S, S, *TransportSet], universe S) []S {
+	outbound := ds.GroupByS1(t)
+	srcGroups := make([]S, len(outbound))
+	for i, p := range outbound {
+		srcGroups[i] = p.Left
+	}
+	inbound := ds.GroupByS2(t)
+	dstGroups := make([]S, len(inbound))
+	for i, p := range inbound {
+		dstGroups[i] = p.Left
+	}
+	res := ds.Refine(srcGroups, dstGroups)
+	unmentioned := universe
+	for _, m := range res {
+		unmentioned = unmentioned.Subtract(m)
+	}
+	if !unmentioned.IsEmpty() {
+		res = append(res, unmentioned)
+	}
+	return res
+}
+
+// restrictEndpoints returns the connections of t from the given endpoints (if src is true) or to them (otherwise)
+func restrictEndpoints[S ds.Set[S]](t ds.TripleSet[S, S, *TransportSet], endpoints S, src bool) ds.TripleSet[S, S, *TransportSet] {
+	var res ds.TripleSet[S, S, *TransportSet] = ds.NewLeftTripleSet[S, S, *TransportSet]()
+	for _, cube := range t.Partitions() {
+		if src {
+			cube.S1 = cube.S1.Intersect(endpoints)
+		} else {
+			cube.S2 = cube.S2.Intersect(endpoints)
+		}
+		res = res.Union(ds.CartesianLeftTriple(cube.S1, cube.S2, cube.S3))
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/interval"
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func TestEndpointsTrafficSetEquivalenceClasses(t *testing.T) {
+	web, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	db, _ := netset.IPBlockFromCidr("10.240.20.0/24")
+	admin, _ := netset.IPBlockFromIPAddress("10.240.10.5")
+	tcp5432 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 5432, 5432)
+	tcp22 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22)
+
+	conns := netset.NewEndpointsTrafficSet(web, db, tcp5432).Union(netset.NewEndpointsTrafficSet(admin, db, tcp22))
+	classes := conns.EquivalenceClasses()
+	require.Len(t, classes, 4)
+
+	// the addresses not in the set form a class with no connections
+	require.True(t, classes[0].Members.Equal(web.Union(db).Complement()))
+	require.True(t, classes[0].Outbound.IsEmpty())
+	require.True(t, classes[0].Inbound.IsEmpty())
+
+	require.True(t, classes[1].Members.Equal(web.Subtract(admin)))
+	require.True(t, classes[1].Outbound.Equal(netset.NewEndpointsTrafficSet(web.Subtract(admin), db, tcp5432)))
+	require.True(t, classes[1].Inbound.IsEmpty())
+
+	require.True(t, classes[2].Members.Equal(admin))
+	require.True(t, classes[2].Outbound.Equal(netset.NewEndpointsTrafficSet(admin, db, tcp5432.Union(tcp22))))
+
+	require.True(t, classes[3].Members.Equal(db))
+	require.True(t, classes[3].Outbound.IsEmpty())
+	require.True(t, classes[3].Inbound.Equal(conns))
+
+	bddClasses := conns.WithBackend(netset.BDDBackend).EquivalenceClasses()
+	require.Len(t, bddClasses, len(classes))
+	require.Equal(t, netset.BDDBackend, bddClasses[2].Outbound.Backend())
+	require.True(t, bddClasses[2].Outbound.Equal(classes[2].Outbound))
+
+	require.Len(t, netset.EmptyEndpointsTrafficSet().EquivalenceClasses(), 1)
+	require.True(t, netset.EmptyEndpointsTrafficSet().EquivalenceClasses()[0].Members.IsAll())
+}
+
+func TestDiscreteEndpointsTrafficSetEquivalenceClasses(t *testing.T) {
+	// 0,1 <-> 2,3 over ICMP, and 4 -> 2 over ICMP
+	a, b := interval.New(0, 1).ToSet(), interval.New(2, 3).ToSet()
+	icmp := netset.AllICMPTransport()
+	conns := netset.NewDiscreteEndpointsTrafficSet(a, b, icmp).Union(
+		netset.NewDiscreteEndpointsTrafficSet(b, a, icmp)).Union(
+		netset.NewDiscreteEndpointsTrafficSet(interval.New(4, 4).ToSet(), interval.New(2, 2).ToSet(), icmp))
+
+	classes := conns.EquivalenceClasses()
+	members := make([]string, len(classes))
+	for i, c := range classes {
+		members[i] = c.Members.String()
+	}
+	require.Equal(t, []string{"0-1", "2", "3", "4", "5-4294967295"}, members)
+	require.True(t, classes[0].Inbound.Equal(netset.NewDiscreteEndpointsTrafficSet(b, a, icmp)))
+	require.True(t, classes[4].Outbound.IsEmpty())
+	require.True(t, classes[4].Inbound.IsEmpty())
+
+	r := netset.NewEndpointRegistry()
+	r.Register("a0", "a1", "b2", "b3", "c4")
+	for _, c := range conns.WithRegistry(r).EquivalenceClasses() {
+		require.Same(t, r, c.Outbound.Registry())
+		require.Same(t, r, c.Inbound.Registry())
+	}
+}
@@ -8,7 +8,9 @@ package netset
 
 import (
 	"fmt"
-	"sort"
+	"math/big"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/np-guard/models/pkg/ds"
@@ -55,6 +57,16 @@ func (c *ICMPSet) Size() int {
 	return c.props.Size()
 }
 
+// BigSize returns the exact number of (type, code) pairs in the set
+func (c *ICMPSet) BigSize() *big.Int {
+	return c.props.BigSize()
+}
+
+// Compare returns -1 if c<other, 1 if c>other, 0 o.w.
+func (c *ICMPSet) Compare(other *ICMPSet) int {
+	return c.props.Compare(other.props)
+}
+
 // Subtract returns the subtraction of the other from c
 func (c *ICMPSet) Subtract(other *ICMPSet) *ICMPSet {
 	return &ICMPSet{props: c.props.Subtract(other.props)}
@@ -114,22 +126,143 @@ func (c *ICMPSet) IsAll() bool {
 	return c.Equal(allICMP)
 }
 
-func getICMPCubeStr(cube ds.Pair[*TypeSet, *CodeSet]) string {
-	if cube.Right.Equal(AllICMPCodes()) {
-		return fmt.Sprintf("ICMP type: %s", cube.Left.String())
+// Universe returns the set of all ICMP type and code pairs
+func (c *ICMPSet) Universe() *ICMPSet {
+	return AllICMPSet()
+}
+
+// Complement returns the ICMP type and code pairs not in this set
+func (c *ICMPSet) Complement() *ICMPSet {
+	return AllICMPSet().Subtract(c)
+}
+
+// icmpSpan is a range of ICMP types with a range of their codes, or with all their codes if codes is nil
+type icmpSpan struct {
+	types interval.Interval
+	codes *interval.Interval
+}
+
+// String returns the symbolic form of the span, "types" or "types/codes", naming single types and codes where possible
+func (s icmpSpan) String() string {
+	singleType := s.types.Start() == s.types.End()
+	res := s.types.ShortString()
+	if name, ok := netp.ICMPTypeName(int(s.types.Start())); ok && singleType {
+		res = name
+	}
+	if s.codes == nil {
+		return res
+	}
+	codes := s.codes.ShortString()
+	if name, ok := netp.ICMPCodeName(int(s.types.Start()), int(s.codes.Start())); ok && singleType && s.codes.Size() == 1 {
+		codes = name
+	}
+	return res + netp.ICMPTypeCodeSeparator + codes
+}
+
+func (s icmpSpan) compare(other icmpSpan) int {
+	if c := s.types.Compare(other.types); c != 0 || s.codes == nil || other.codes == nil {
+		return c
+	}
+	return s.codes.Compare(*other.codes)
+}
+
+func (c *ICMPSet) spans() []icmpSpan {
+	var res []icmpSpan
+	for _, cube := range c.Partitions() {
+		for _, types := range cube.Left.Intervals() {
+			if cube.Right.Equal(AllICMPCodes()) {
+				res = append(res, icmpSpan{types: types})
+				continue
+			}
+			for _, codes := range cube.Right.Intervals() {
+				res = append(res, icmpSpan{types: types, codes: &codes})
+			}
+		}
 	}
-	return fmt.Sprintf("ICMP type: %s code: %s", cube.Left.String(), cube.Right.String())
+	slices.SortFunc(res, icmpSpan.compare)
+	return res
 }
 
+// String returns the symbolic form of the set: "ICMP" followed by a comma separated list of types and type/code pairs,
+// where single types and codes are named as in RFC 792 (e.g., "ICMP echo-request,dest-unreachable/port-unreachable").
+// Types for which all codes are in the set are written without codes.
 func (c *ICMPSet) String() string {
 	if c.IsAll() {
 		return string(netp.ProtocolStringICMP)
 	}
-	cubes := c.Partitions()
-	var resStrings = make([]string, len(cubes))
-	for i, cube := range cubes {
-		resStrings[i] = getICMPCubeStr(cube)
+	if c.IsEmpty() {
+		return ""
+	}
+	spans := c.spans()
+	var resStrings = make([]string, len(spans))
+	for i, span := range spans {
+		resStrings[i] = span.String()
+	}
+	return string(netp.ProtocolStringICMP) + " " + strings.Join(resStrings, comma)
+}
+
+// parseICMPRange parses a single value, using the parse function, or a range of numbers "start-end".
+// The result should be within [minValue, maxValue].
+func parseICMPRange(s string, parse func(string) (int, error), minValue, maxValue int) (interval.Interval, error) {
+	start, err := parse(s)
+	end := start
+	if err != nil {
+		startStr, endStr, isRange := strings.Cut(s, "-")
+		var startErr, endErr error
+		start, startErr = strconv.Atoi(startStr)
+		end, endErr = strconv.Atoi(endStr)
+		if !isRange || startErr != nil || endErr != nil || start > end {
+			return interval.Interval{}, err
+		}
+	}
+	if start < minValue || end > maxValue {
+		return interval.Interval{}, fmt.Errorf("%s is out of range [%d-%d]", s, minValue, maxValue)
+	}
+	return interval.New(int64(start), int64(end)), nil
+}
+
+// parseICMPSpan parses a range of types with an optional range of codes, in the form returned by icmpSpan.String
+func parseICMPSpan(s string) (*ICMPSet, error) {
+	typesStr, codesStr, hasCodes := strings.Cut(strings.TrimSpace(s), netp.ICMPTypeCodeSeparator)
+	types, err := parseICMPRange(typesStr, netp.ParseICMPType, netp.MinICMPType, netp.MaxICMPType)
+	if err != nil {
+		return nil, err
+	}
+	if !hasCodes {
+		return icmpPropsPathLeft(types.ToSet(), AllICMPCodes()), nil
+	}
+	parseCode := func(code string) (int, error) {
+		if types.Start() != types.End() {
+			return strconv.Atoi(code)
+		}
+		return netp.ParseICMPCode(int(types.Start()), code)
+	}
+	codes, err := parseICMPRange(codesStr, parseCode, netp.MinICMPCode, netp.MaxICMPCode)
+	if err != nil {
+		return nil, err
+	}
+	return icmpPropsPathLeft(types.ToSet(), codes.ToSet()), nil
+}
+
+// ParseICMPSet parses an ICMPSet, in the form returned by String. The "ICMP" prefix is optional.
+// Types and codes may be given as symbolic names, numbers or ranges of numbers;
+// for example, "echo-request,dest-unreachable/port-unreachable,13-14,5/0-1".
+func ParseICMPSet(s string) (*ICMPSet, error) {
+	s = strings.TrimSpace(s)
+	if s == string(netp.ProtocolStringICMP) {
+		return AllICMPSet(), nil
+	}
+	s = strings.TrimPrefix(s, string(netp.ProtocolStringICMP)+" ")
+	res := EmptyICMPSet()
+	if s == "" {
+		return res, nil
+	}
+	for _, spanStr := range strings.Split(s, comma) {
+		span, err := parseICMPSpan(spanStr)
+		if err != nil {
+			return nil, err
+		}
+		res = res.Union(span)
 	}
-	sort.Strings(resStrings)
-	return strings.Join(resStrings, " | ")
+	return res, nil
 }
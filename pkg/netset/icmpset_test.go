@@ -12,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/np-guard/models/pkg/ds"
 	"github.com/np-guard/models/pkg/netp"
 	"github.com/np-guard/models/pkg/netset"
 )
@@ -25,7 +26,7 @@ func TestBasicICMPSetStrict(t *testing.T) {
 	icmpset := netset.NewICMPSetStrict(obj1)
 
 	// test basic functions, operations
-	fmt.Println(icmpset) // ICMP icmp-type: 8 icmp-code: 0
+	fmt.Println(icmpset) // ICMP echo-request
 	fmt.Println(all)     // ICMP
 	res := icmpset.Union(all)
 	fmt.Println(res) // ICMP
@@ -36,8 +37,8 @@ func TestBasicICMPSetStrict(t *testing.T) {
 }
 
 func TestBasicICMPSet(t *testing.T) {
-	icmpset := netset.NewICMPSet(8, 8, 0, 255) // ICMP type: 8
-	icmpset1 := netset.NewICMPSet(8, 8, 0, 0)  // ICMP type: 8 code: 0
+	icmpset := netset.NewICMPSet(8, 8, 0, 255) // ICMP echo-request
+	icmpset1 := netset.NewICMPSet(8, 8, 0, 0)  // ICMP echo-request/0
 	fmt.Println(icmpset)
 	fmt.Println(icmpset1)
 
@@ -53,3 +54,82 @@ func TestBasicICMPSet(t *testing.T) {
 
 	fmt.Println("done")
 }
+
+func TestICMPSetString(t *testing.T) {
+	require.Equal(t, "ICMP echo-request", netset.NewICMPSet(netp.Echo, netp.Echo, 0, 255).String())
+	require.Equal(t, "ICMP echo-request/0", netset.NewICMPSet(netp.Echo, netp.Echo, 0, 0).String())
+	require.Equal(t, "ICMP dest-unreachable/port-unreachable,time-exceeded/0-1",
+		netset.NewICMPSet(3, 3, 3, 3).Union(netset.NewICMPSet(11, 11, 0, 1)).String())
+	require.Equal(t, "ICMP 100-200/7", netset.NewICMPSet(100, 200, 7, 7).String())
+	require.Equal(t, "", netset.EmptyICMPSet().String())
+
+	strict := netset.NewICMPSetStrict(netp.ICMP{TypeCode: &netp.ICMPTypeCode{Type: netp.DestinationUnreachable}})
+	require.Equal(t, "ICMP dest-unreachable", strict.String())
+	code := 1
+	strict = strict.Union(netset.NewICMPSetStrict(netp.ICMP{TypeCode: &netp.ICMPTypeCode{Type: netp.Redirect, Code: &code}}))
+	strict = strict.Union(netset.NewICMPSetStrict(netp.ICMP{TypeCode: &netp.ICMPTypeCode{Type: netp.Echo}}))
+	require.Equal(t, "ICMP dest-unreachable,redirect/host-redirect,echo-request", strict.String())
+	require.Equal(t, "ICMP", netset.AllICMPSetStrict().String())
+}
+
+func TestParseICMPSet(t *testing.T) {
+	s, err := netset.ParseICMPSet("ICMP echo-request, dest-unreachable/port-unreachable,13-14,5/0-1,200/7")
+	require.Nil(t, err)
+	expected := netset.NewICMPSet(netp.Echo, netp.Echo, 0, 255).Union(netset.NewICMPSet(3, 3, 3, 3)).
+		Union(netset.NewICMPSet(13, 14, 0, 255)).Union(netset.NewICMPSet(5, 5, 0, 1)).Union(netset.NewICMPSet(200, 200, 7, 7))
+	require.True(t, s.Equal(expected))
+
+	for _, set := range []*netset.ICMPSet{expected, netset.AllICMPSet(), netset.EmptyICMPSet(),
+		netset.AllICMPSet().Subtract(netset.NewICMPSet(3, 3, 5, 5))} {
+		parsed, err := netset.ParseICMPSet(set.String())
+		require.Nil(t, err)
+		require.True(t, parsed.Equal(set), set.String())
+	}
+
+	for _, invalid := range []string{"echo", "dest-unreachable/echo-request", "3-5/port-unreachable", "255", "3/256", "5-3"} {
+		_, err := netset.ParseICMPSet(invalid)
+		require.NotNil(t, err, invalid)
+	}
+}
+
+func TestParseICMPSetStrict(t *testing.T) {
+	s, err := netset.ParseICMPSetStrict("echo-request,dest-unreachable/3,time-exceeded/ttl-exceeded,time-exceeded/1,13")
+	require.Nil(t, err)
+	require.Equal(t, "ICMP dest-unreachable/port-unreachable,echo-request,time-exceeded,timestamp-request", s.String())
+
+	for _, set := range []*netset.RFCICMPSet{s, netset.AllICMPSetStrict(), netset.EmptyICMPSetStrict()} {
+		parsed, err := netset.ParseICMPSetStrict(set.String())
+		require.Nil(t, err)
+		require.True(t, parsed.Equal(set), set.String())
+	}
+
+	for _, invalid := range []string{"echo-request/1", "dest-unreachable/6", "7", "redirect/ttl-exceeded", "3-5"} {
+		_, err := netset.ParseICMPSetStrict(invalid)
+		require.NotNil(t, err, invalid)
+	}
+}
+
+func TestRFCICMPSetBitSet(t *testing.T) {
+	enum := netset.ICMPEnumeration()
+	require.Equal(t, netset.AllICMPSetStrict().Size(), enum.Size())
+	for i := 0; i < enum.Size(); i++ {
+		icmp := enum.Value(i)
+		index, ok := enum.Index(icmp)
+		require.True(t, ok)
+		require.Equal(t, i, index)
+		require.True(t, netset.NewICMPSetStrict(icmp).BitSet().Equal(ds.BitSetFromIndices(enum, i)), icmp.String())
+	}
+	_, ok := enum.Index(netp.ICMP{TypeCode: &netp.ICMPTypeCode{Type: netp.DestinationUnreachable}})
+	require.False(t, ok)
+
+	a, err := netset.ParseICMPSetStrict("echo-request,dest-unreachable,redirect/host-redirect")
+	require.Nil(t, err)
+	b, err := netset.ParseICMPSetStrict("echo-reply,dest-unreachable/port-unreachable,redirect")
+	require.Nil(t, err)
+	require.Equal(t, "{dest-unreachable/net-unreachable, dest-unreachable/host-unreachable, dest-unreachable/protocol-unreachable, "+
+		"dest-unreachable/fragmentation-needed, dest-unreachable/source-route-failed, echo-request}", a.BitSet().Subtract(b.BitSet()).String())
+	require.True(t, netset.RFCICMPSetFromBitSet(a.BitSet().Union(b.BitSet())).Equal(a.Union(b)))
+	require.True(t, netset.RFCICMPSetFromBitSet(a.BitSet().Intersect(b.BitSet())).Equal(a.Intersect(b)))
+	require.True(t, netset.RFCICMPSetFromBitSet(a.BitSet().Complement()).Equal(a.Complement()))
+	require.Equal(t, a.Compare(b), a.BitSet().Compare(b.BitSet()))
+}
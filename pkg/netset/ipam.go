@@ -0,0 +1,123 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+
+	"github.com/np-guard/models/pkg/interval"
+)
+
+// this file defines IPAM operations on a pool of IP addresses: allocation of free CIDRs, and planning of subnets
+
+// cidrSize returns the number of addresses of a CIDR with the given prefix length
+func cidrSize(prefixLength int) (int64, error) {
+	if prefixLength < 0 || prefixLength > maxIPv4Bits {
+		return 0, fmt.Errorf("prefix length must be in the range [0-%d]; got %d", maxIPv4Bits, prefixLength)
+	}
+	return int64(1) << (maxIPv4Bits - prefixLength), nil
+}
+
+func cidrBlock(start, size int64) *IPBlock {
+	return &IPBlock{ipRange: interval.New(start, start+size-1).ToSet()}
+}
+
+// free returns the addresses of the pool b that are not in any of the used blocks
+func (b *IPBlock) free(used []*IPBlock) *IPBlock {
+	res := b
+	for _, block := range used {
+		res = res.Subtract(block)
+	}
+	return res
+}
+
+// FreeCidrs returns the addresses of the pool b that are not in any of the used blocks, as a minimal list of CIDRs
+func (b *IPBlock) FreeCidrs(used ...*IPBlock) []*IPBlock {
+	return b.free(used).SplitToCidrs()
+}
+
+// FirstFreeCidr returns the CIDR with the given prefix length and the lowest address, that is contained in the pool b
+// and does not overlap any of the used blocks. It returns an error if there is no such CIDR.
+func (b *IPBlock) FirstFreeCidr(prefixLength int, used ...*IPBlock) (*IPBlock, error) {
+	size, err := cidrSize(prefixLength)
+	if err != nil {
+		return nil, err
+	}
+	for _, span := range b.free(used).ipRange.Intervals() {
+		// the first address in the span that is aligned to the size of the CIDR
+		start := (span.Start() + size - 1) / size * size
+		if start+size-1 <= span.End() {
+			return cidrBlock(start, size), nil
+		}
+	}
+	return nil, fmt.Errorf("no free /%d CIDR in %s", prefixLength, b)
+}
+
+// BestFitFreeCidr returns a CIDR with the given prefix length, that is contained in the pool b and does not overlap
+// any of the used blocks. The CIDR is the first of the smallest free CIDR that can hold it, so that allocations
+// leave large free CIDRs intact. It returns an error if there is no such CIDR.
+func (b *IPBlock) BestFitFreeCidr(prefixLength int, used ...*IPBlock) (*IPBlock, error) {
+	size, err := cidrSize(prefixLength)
+	if err != nil {
+		return nil, err
+	}
+	var best *IPBlock
+	for _, cidr := range b.FreeCidrs(used...) {
+		if cidr.ipCount() >= int(size) && (best == nil || cidr.ipCount() < best.ipCount()) {
+			best = cidr
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no free /%d CIDR in %s", prefixLength, b)
+	}
+	return cidrBlock(best.ipRange.Min(), size), nil
+}
+
+// SplitEqual splits the CIDR b into n CIDRs of equal size, by their order. n must be a power of two, such that the
+// resulting CIDRs have a prefix length of at most 32.
+func (b *IPBlock) SplitEqual(n int) ([]*IPBlock, error) {
+	addr, length, err := cidrPrefix(b)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("cannot split %s into %d equal CIDRs: not a power of two", b, n)
+	}
+	size, err := cidrSize(length + bits.TrailingZeros(uint(n))) //nolint:gosec // n is positive
+	if err != nil {
+		return nil, fmt.Errorf("cannot split %s into %d equal CIDRs: %w", b, n, err)
+	}
+	res := make([]*IPBlock, n)
+	for i := range res {
+		res[i] = cidrBlock(int64(addr)+int64(i)*size, size)
+	}
+	return res, nil
+}
+
+// Carve allocates a CIDR for each of the given prefix lengths from the pool b, without overlapping the used blocks
+// or each other, and returns the CIDRs in the order of the prefix lengths. To minimize fragmentation, the largest
+// CIDRs are allocated first, each by BestFitFreeCidr. It returns an error if some CIDR cannot be allocated.
+func (b *IPBlock) Carve(prefixLengths []int, used ...*IPBlock) ([]*IPBlock, error) {
+	order := make([]int, len(prefixLengths))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int { return prefixLengths[i] - prefixLengths[j] })
+	res := make([]*IPBlock, len(prefixLengths))
+	used = slices.Clone(used)
+	for _, i := range order {
+		cidr, err := b.BestFitFreeCidr(prefixLengths[i], used...)
+		if err != nil {
+			return nil, fmt.Errorf("prefix length %d (index %d): %w", prefixLengths[i], i, err)
+		}
+		res[i] = cidr
+		used = append(used, cidr)
+	}
+	return res, nil
+}
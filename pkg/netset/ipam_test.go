@@ -0,0 +1,88 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func cidrStrings(blocks []*netset.IPBlock) []string {
+	res := make([]string, len(blocks))
+	for i, b := range blocks {
+		res[i] = b.String()
+	}
+	return res
+}
+
+func TestFreeCidrAllocation(t *testing.T) {
+	pool, _ := netset.IPBlockFromCidr("10.0.0.0/16")
+	used1, _ := netset.IPBlockFromCidr("10.0.0.0/24")
+	used2, _ := netset.IPBlockFromIPRangeStr("10.0.1.0-10.0.1.200")
+	used3, _ := netset.IPBlockFromCidr("10.0.2.128/25")
+
+	require.Equal(t, []string{"10.0.1.201", "10.0.1.202/31", "10.0.1.204/30", "10.0.1.208/28", "10.0.1.224/27",
+		"10.0.2.0/25", "10.0.3.0/24", "10.0.4.0/22", "10.0.8.0/21", "10.0.16.0/20", "10.0.32.0/19", "10.0.64.0/18",
+		"10.0.128.0/17"}, cidrStrings(pool.FreeCidrs(used1, used2, used3)))
+
+	first, err := pool.FirstFreeCidr(24, used1, used2, used3)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.3.0/24", first.String())
+	first, err = pool.FirstFreeCidr(28, used1, used2, used3)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.1.208/28", first.String())
+
+	// the smallest free CIDR that can hold a /26 is 10.0.2.0/25
+	best, err := pool.BestFitFreeCidr(26, used1, used2, used3)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.2.0/26", best.String())
+	best, err = pool.BestFitFreeCidr(20, used1, used2, used3)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.16.0/20", best.String())
+
+	_, err = pool.FirstFreeCidr(16, used1)
+	require.EqualError(t, err, "no free /16 CIDR in 10.0.0.0/16")
+	_, err = pool.BestFitFreeCidr(17, used1, netset.GetCidrAll())
+	require.EqualError(t, err, "no free /17 CIDR in 10.0.0.0/16")
+	_, err = pool.FirstFreeCidr(33)
+	require.EqualError(t, err, "prefix length must be in the range [0-32]; got 33")
+}
+
+func TestSplitEqual(t *testing.T) {
+	cidr, _ := netset.IPBlockFromCidr("10.240.0.0/16")
+	subnets, err := cidr.SplitEqual(4)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.240.0.0/18", "10.240.64.0/18", "10.240.128.0/18", "10.240.192.0/18"}, cidrStrings(subnets))
+	subnets, err = cidr.SplitEqual(1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.240.0.0/16"}, cidrStrings(subnets))
+
+	_, err = cidr.SplitEqual(3)
+	require.EqualError(t, err, "cannot split 10.240.0.0/16 into 3 equal CIDRs: not a power of two")
+	_, err = cidr.SplitEqual(1 << 17)
+	require.EqualError(t, err, "cannot split 10.240.0.0/16 into 131072 equal CIDRs: prefix length must be in the range [0-32]; got 33")
+	notCidr, _ := netset.IPBlockFromIPRangeStr("10.0.0.1-10.0.0.2")
+	_, err = notCidr.SplitEqual(2)
+	require.Error(t, err)
+}
+
+func TestCarve(t *testing.T) {
+	pool, _ := netset.IPBlockFromCidr("10.0.0.0/22")
+	used, _ := netset.IPBlockFromCidr("10.0.0.0/26")
+	// the CIDRs are allocated from the largest, and returned in the requested order
+	cidrs, err := pool.Carve([]int{26, 24, 23, 25}, used)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.64/26", "10.0.1.0/24", "10.0.2.0/23", "10.0.0.128/25"}, cidrStrings(cidrs))
+	require.Equal(t, []string{"10.0.0.64/26"}, cidrStrings(pool.FreeCidrs(append(cidrs[1:], used)...)))
+	require.Empty(t, pool.FreeCidrs(append(cidrs, used)...))
+
+	_, err = pool.Carve([]int{23, 23}, used)
+	require.EqualError(t, err, "prefix length 23 (index 1): no free /23 CIDR in 10.0.0.0/22")
+}
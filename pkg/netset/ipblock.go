@@ -7,10 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package netset
 
 import (
+	"cmp"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"net"
 	"sort"
 	"strconv"
@@ -104,6 +106,11 @@ func (b *IPBlock) Size() int {
 	return b.ipRange.Size()
 }
 
+// BigSize returns the exact number of IP addresses in the block
+func (b *IPBlock) BigSize() *big.Int {
+	return b.ipRange.BigSize()
+}
+
 // Subtract returns a new IPBlock from subtraction of input IPBlock from this IPBlock
 func (b *IPBlock) Subtract(c *IPBlock) *IPBlock {
 	if b == c {
@@ -153,8 +160,12 @@ func (b *IPBlock) IsSingleIPAddress() bool {
 }
 
 // Compare returns -1 if this<other, 1 if this>other, 0 o.w.
+// IPBlocks are ordered by their first address, then by their last address, and then lexicographically by their ranges.
+// The empty IPBlock is the smallest IPBlock.
 func (b *IPBlock) Compare(other *IPBlock) int {
 	switch {
+	case b.IsEmpty() || other.IsEmpty():
+		return cmp.Compare(b.ipRange.NumIntervals(), other.ipRange.NumIntervals())
 	case b.ipRange.Min() < other.ipRange.Min():
 		return -1
 	case b.ipRange.Min() > other.ipRange.Min():
@@ -164,7 +175,7 @@ func (b *IPBlock) Compare(other *IPBlock) int {
 	case b.ipRange.Max() > other.ipRange.Max():
 		return 1
 	default:
-		return 0
+		return b.ipRange.Compare(other.ipRange)
 	}
 }
 
@@ -542,3 +553,18 @@ func (b *IPBlock) Complementary() *IPBlock {
 	allIPBlock := GetCidrAll()
 	return allIPBlock.Subtract(b)
 }
+
+// Universe returns the IPBlock of all IPv4 addresses
+func (b *IPBlock) Universe() *IPBlock {
+	return GetCidrAll()
+}
+
+// Complement returns the IPv4 addresses not in this IPBlock (same as Complementary)
+func (b *IPBlock) Complement() *IPBlock {
+	return b.Complementary()
+}
+
+// IsAll returns true if this IPBlock holds all IPv4 addresses
+func (b *IPBlock) IsAll() bool {
+	return b.Equal(GetCidrAll())
+}
@@ -83,6 +83,14 @@ func TestOps(t *testing.T) {
 	require.Equal(t, ipb5.Compare(ipb6), -1)
 	require.Equal(t, ipb2.Compare(ipb1), 1)
 	require.Equal(t, ipb3.Compare(ipb4), 0)
+
+	// blocks with the same first and last addresses are ordered by their ranges
+	hole, _ := netset.IPBlockFromIPAddress("1.2.3.100")
+	withHole := ipb9.Subtract(hole)
+	require.Equal(t, -1, withHole.Compare(ipb9))
+	require.Equal(t, 1, ipb9.Compare(withHole))
+	require.Equal(t, -1, netset.NewIPBlock().Compare(ipb1))
+	require.Equal(t, 0, netset.NewIPBlock().Compare(netset.NewIPBlock()))
 }
 
 func TestConversions(t *testing.T) {
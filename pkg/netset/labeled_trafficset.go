@@ -0,0 +1,144 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/np-guard/models/pkg/ds"
+)
+
+// ruleID is a rule identifier, used as a label in LabeledEndpointsTrafficSet
+type ruleID string
+
+func (r ruleID) Equal(other ruleID) bool {
+	return r == other
+}
+
+func (r ruleID) Copy() ruleID {
+	return r
+}
+
+func (r ruleID) Hash() int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(r))
+	return int(h.Sum32())
+}
+
+type labeledTriples = ds.LabeledSet[ds.TripleSet[*IPBlock, *IPBlock, *TransportSet], ruleID]
+
+// LabeledEndpointsTrafficSet is an EndpointsTrafficSet in which every connection is tagged with the IDs of the
+// rules that allow it. Union merges the rules of overlapping connections, so after union-ing many rules it is
+// possible to tell which rules contributed to each connection.
+type LabeledEndpointsTrafficSet struct {
+	props *labeledTriples
+}
+
+// EmptyLabeledEndpointsTrafficSet returns an empty LabeledEndpointsTrafficSet
+func EmptyLabeledEndpointsTrafficSet() *LabeledEndpointsTrafficSet {
+	return &LabeledEndpointsTrafficSet{props: ds.NewLabeledSet[ds.TripleSet[*IPBlock, *IPBlock, *TransportSet], ruleID]()}
+}
+
+// NewLabeledEndpointsTrafficSet returns a new LabeledEndpointsTrafficSet object from input src, dst IP-ranges sets and
+// TransportSet connections, tagged with the given rule IDs
+func NewLabeledEndpointsTrafficSet(src, dst *IPBlock, conn *TransportSet, rules ...string) *LabeledEndpointsTrafficSet {
+	ids := make([]ruleID, len(rules))
+	for i, r := range rules {
+		ids[i] = ruleID(r)
+	}
+	return &LabeledEndpointsTrafficSet{props: ds.LabeledCartesianTriple(src, dst, conn, ids...)}
+}
+
+// Equal returns true if both objects hold the same connections, tagged with the same rules
+func (c *LabeledEndpointsTrafficSet) Equal(other *LabeledEndpointsTrafficSet) bool {
+	return c.props.Equal(other.props)
+}
+
+// Copy returns a deep copy of this LabeledEndpointsTrafficSet
+func (c *LabeledEndpointsTrafficSet) Copy() *LabeledEndpointsTrafficSet {
+	return &LabeledEndpointsTrafficSet{props: c.props.Copy()}
+}
+
+// IsEmpty returns true of the LabeledEndpointsTrafficSet is empty
+func (c *LabeledEndpointsTrafficSet) IsEmpty() bool {
+	return c.props.IsEmpty()
+}
+
+// Union returns the connections of both this and `other` sets. Connections in both sets are tagged with the rules of both.
+func (c *LabeledEndpointsTrafficSet) Union(other *LabeledEndpointsTrafficSet) *LabeledEndpointsTrafficSet {
+	return &LabeledEndpointsTrafficSet{props: c.props.Union(other.props)}
+}
+
+// Intersect returns the connections common to this and `other` sets, tagged with the rules of both
+func (c *LabeledEndpointsTrafficSet) Intersect(other *LabeledEndpointsTrafficSet) *LabeledEndpointsTrafficSet {
+	return &LabeledEndpointsTrafficSet{props: c.props.Intersect(other.props)}
+}
+
+// Subtract returns the connections of this set that are not in `other`, tagged with their original rules
+func (c *LabeledEndpointsTrafficSet) Subtract(other *LabeledEndpointsTrafficSet) *LabeledEndpointsTrafficSet {
+	return &LabeledEndpointsTrafficSet{props: c.props.Subtract(other.props)}
+}
+
+// Restrict returns the connections of this set that are also in the (unlabeled) traffic set t, tagged with their original rules
+func (c *LabeledEndpointsTrafficSet) Restrict(t *EndpointsTrafficSet) *LabeledEndpointsTrafficSet {
+	return &LabeledEndpointsTrafficSet{props: c.props.Restrict(t.props)}
+}
+
+// TrafficSet returns the connections of this set, without their rules
+func (c *LabeledEndpointsTrafficSet) TrafficSet() *EndpointsTrafficSet {
+	props := c.props.Region(ds.NewLeftTripleSet[*IPBlock, *IPBlock, *TransportSet]())
+	return newTrafficSet(props, strictTransports(props.Partitions()))
+}
+
+// Rules returns the sorted IDs of the rules that allow at least some of the connections in t
+func (c *LabeledEndpointsTrafficSet) Rules(t *EndpointsTrafficSet) []string {
+	return rulesList(c.props.Labels(t.props))
+}
+
+// RulesFor returns the sorted IDs of the rules that allow at least some of the connections from src to dst over conn
+func (c *LabeledEndpointsTrafficSet) RulesFor(src, dst *IPBlock, conn *TransportSet) []string {
+	return c.Rules(NewEndpointsTrafficSet(src, dst, conn))
+}
+
+func rulesList(ids *ds.HashSet[ruleID]) []string {
+	items := ids.Items()
+	res := make([]string, len(items))
+	for i, id := range items {
+		res[i] = string(id)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Partitions returns the cubes of this set, each paired with the sorted IDs of the rules that allow it.
+// The cubes are sorted by ds.CompareTriples.
+func (c *LabeledEndpointsTrafficSet) Partitions() []ds.Pair[ds.Triple[*IPBlock, *IPBlock, *TransportSet], []string] {
+	var res []ds.Pair[ds.Triple[*IPBlock, *IPBlock, *TransportSet], []string]
+	for _, region := range c.props.Partitions() {
+		rules := rulesList(region.Right)
+		for _, cube := range region.Left.Partitions() {
+			res = append(res, ds.Pair[ds.Triple[*IPBlock, *IPBlock, *TransportSet], []string]{Left: cube, Right: rules})
+		}
+	}
+	slices.SortFunc(res, func(a, b ds.Pair[ds.Triple[*IPBlock, *IPBlock, *TransportSet], []string]) int {
+		return ds.CompareTriples(a.Left, b.Left)
+	})
+	return res
+}
+
+func (c *LabeledEndpointsTrafficSet) String() string {
+	cubes := c.Partitions()
+	var resStrings = make([]string, len(cubes))
+	for i, cube := range cubes {
+		resStrings[i] = cubeStr(cube.Left) + ", rules: " + strings.Join(cube.Right, " ")
+	}
+	sort.Strings(resStrings)
+	return strings.Join(resStrings, semicolon)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func TestLabeledEndpointsTrafficSet(t *testing.T) {
+	subnet1, _ := netset.IPBlockFromCidr("10.1.1.0/24")
+	subnet2, _ := netset.IPBlockFromCidr("10.2.2.0/24")
+	host1, _ := netset.IPBlockFromIPAddress("10.1.1.1")
+	host2, _ := netset.IPBlockFromIPAddress("10.2.2.2")
+	tcp22 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22)
+
+	rules := netset.NewLabeledEndpointsTrafficSet(subnet1, subnet2, netset.AllTCPTransport(), "allow-tcp").Union(
+		netset.NewLabeledEndpointsTrafficSet(host1, netset.GetCidrAll(), tcp22, "allow-ssh")).Union(
+		netset.NewLabeledEndpointsTrafficSet(subnet1, subnet2, netset.AllUDPTransport(), "allow-udp"))
+	fmt.Println(rules)
+
+	require.Equal(t, []string{"allow-ssh", "allow-tcp"}, rules.RulesFor(host1, host2, tcp22))
+	require.Equal(t, []string{"allow-ssh", "allow-tcp", "allow-udp"}, rules.RulesFor(subnet1, subnet2, netset.AllTransports()))
+	require.Equal(t, []string{"allow-tcp", "allow-udp"}, rules.RulesFor(subnet1, host2, netset.AllUDPTransport().Union(netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 80, 80))))
+	require.Empty(t, rules.RulesFor(host2, host1, netset.AllTransports()))
+
+	expected := netset.NewEndpointsTrafficSet(subnet1, subnet2, netset.AllTCPTransport().Union(netset.AllUDPTransport())).Union(
+		netset.NewEndpointsTrafficSet(host1, netset.GetCidrAll(), tcp22))
+	require.True(t, rules.TrafficSet().Equal(expected))
+
+	// removing the ssh rule traffic keeps only the tcp/udp rules
+	withoutSSH := rules.Subtract(netset.NewLabeledEndpointsTrafficSet(host1, netset.GetCidrAll(), tcp22, "deny-ssh"))
+	require.Equal(t, []string{"allow-tcp", "allow-udp"}, withoutSSH.Rules(withoutSSH.TrafficSet()))
+
+	restricted := rules.Restrict(netset.NewEndpointsTrafficSet(host1, host2, netset.AllTransports()))
+	for _, cube := range restricted.Partitions() {
+		require.True(t, cube.Left.S1.Equal(host1))
+		require.True(t, cube.Left.S2.Equal(host2))
+	}
+	require.Len(t, restricted.Partitions(), 3)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/ds/settest"
+	"github.com/np-guard/models/pkg/interval"
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+const lawsIterations = 100
+
+// randomRange returns a short range in [low, low+20], so that random sets overlap
+func randomRange(r *rand.Rand, low int64) (start, end int64) {
+	start = low + r.Int64N(16)
+	return start, start + r.Int64N(5)
+}
+
+// randomUnion returns the union of up to 3 sets generated by gen
+func randomUnion[S interface{ Union(S) S }](r *rand.Rand, empty S, gen func(*rand.Rand) S) S {
+	res := empty
+	for range r.IntN(4) {
+		res = res.Union(gen(r))
+	}
+	return res
+}
+
+func randomIPBlock(r *rand.Rand) *netset.IPBlock {
+	return randomUnion(r, netset.NewIPBlock(), func(r *rand.Rand) *netset.IPBlock {
+		start, end := randomRange(r, 0)
+		res, _ := netset.IPBlockFromIPRangeStr(fmt.Sprintf("10.0.0.%d-10.0.0.%d", start, end))
+		return res
+	})
+}
+
+func randomTCPUDPSet(r *rand.Rand) *netset.TCPUDPSet {
+	return randomUnion(r, netset.EmptyTCPorUDPSet(), func(r *rand.Rand) *netset.TCPUDPSet {
+		protocol := []netp.ProtocolString{netp.ProtocolStringTCP, netp.ProtocolStringUDP}[r.IntN(2)]
+		srcMin, srcMax := randomRange(r, netp.MinPort)
+		dstMin, dstMax := randomRange(r, netp.MinPort)
+		return netset.NewTCPorUDPSet(protocol, srcMin, srcMax, dstMin, dstMax)
+	})
+}
+
+func randomICMPSet(r *rand.Rand) *netset.ICMPSet {
+	return randomUnion(r, netset.EmptyICMPSet(), func(r *rand.Rand) *netset.ICMPSet {
+		minType, maxType := randomRange(r, 0)
+		minCode, maxCode := randomRange(r, 0)
+		return netset.NewICMPSet(minType, maxType, minCode, maxCode)
+	})
+}
+
+func randomRFCICMPSet(r *rand.Rand) *netset.RFCICMPSet {
+	var indices []int
+	for i := range netset.ICMPEnumeration().Size() {
+		if r.IntN(2) == 0 {
+			indices = append(indices, i)
+		}
+	}
+	return netset.RFCICMPSetFromBitSet(ds.BitSetFromIndices(netset.ICMPEnumeration(), indices...))
+}
+
+func randomTransportSet(r *rand.Rand) *netset.TransportSet {
+	return netset.NewTCPUDPTransportFromTCPUDPSet(randomTCPUDPSet(r)).Union(netset.NewICMPTransportFromICMPSet(randomICMPSet(r)))
+}
+
+func randomStrictTransportSet(r *rand.Rand) *netset.TransportSet {
+	return randomTransportSet(r).Strict()
+}
+
+// randomMixedTransportSet returns a random set whose strictness is chosen at random
+func randomMixedTransportSet(r *rand.Rand) *netset.TransportSet {
+	return settest.Variants(randomTransportSet, (*netset.TransportSet).Strict)(r)
+}
+
+func randomEndpointsTrafficSet(r *rand.Rand) *netset.EndpointsTrafficSet {
+	return randomTrafficSet(r, randomTransportSet)
+}
+
+// randomTrafficSet returns a random set, which is strict if the transports of its generator are
+func randomTrafficSet(r *rand.Rand, transports settest.Generator[*netset.TransportSet]) *netset.EndpointsTrafficSet {
+	empty := netset.NewEndpointsTrafficSet(netset.NewIPBlock(), netset.NewIPBlock(), transports(r))
+	return randomUnion(r, empty, func(r *rand.Rand) *netset.EndpointsTrafficSet {
+		return netset.NewEndpointsTrafficSet(randomIPBlock(r), randomIPBlock(r), transports(r))
+	})
+}
+
+func toBDD(s *netset.EndpointsTrafficSet) *netset.EndpointsTrafficSet {
+	return s.WithBackend(netset.BDDBackend)
+}
+
+func randomDiscreteEndpointsTrafficSet(r *rand.Rand) *netset.DiscreteEndpointsTrafficSet {
+	return randomUnion(r, netset.EmptyDiscreteEndpointsTrafficSet(), func(r *rand.Rand) *netset.DiscreteEndpointsTrafficSet {
+		srcMin, srcMax := randomRange(r, 0)
+		dstMin, dstMax := randomRange(r, 0)
+		return netset.NewDiscreteEndpointsTrafficSet(interval.New(srcMin, srcMax).ToSet(), interval.New(dstMin, dstMax).ToSet(),
+			randomTransportSet(r))
+	})
+}
+
+func TestSetLaws(t *testing.T) {
+	settest.CheckUniversal(t, randomIPBlock, lawsIterations)
+	settest.CheckUniversal(t, randomTCPUDPSet, lawsIterations)
+	settest.CheckUniversal(t, randomICMPSet, lawsIterations)
+	settest.CheckUniversal(t, randomRFCICMPSet, lawsIterations)
+	settest.CheckUniversal(t, randomTransportSet, lawsIterations)
+	settest.CheckUniversal(t, randomStrictTransportSet, lawsIterations)
+	settest.CheckUniversal(t, randomEndpointsTrafficSet, lawsIterations)
+	settest.CheckUniversal(t, randomDiscreteEndpointsTrafficSet, lawsIterations)
+}
+
+// TestMixedSetLaws checks the laws on operands of mixed strictness, whose universes differ, and on operands of
+// mixed backends, which represent the same sets
+func TestMixedSetLaws(t *testing.T) {
+	settest.Check(t, randomMixedTransportSet, lawsIterations)
+	// strict sets and their non-strict versions hold the same connections
+	settest.Check(t, settest.Variants(randomStrictTransportSet, (*netset.TransportSet).NonStrict), lawsIterations)
+	settest.Check(t, func(r *rand.Rand) *netset.EndpointsTrafficSet { return randomTrafficSet(r, randomMixedTransportSet) },
+		lawsIterations)
+
+	settest.CheckUniversal(t, settest.Variants(randomEndpointsTrafficSet, toBDD), lawsIterations)
+	settest.CheckUniversal(t, settest.Variants(func(r *rand.Rand) *netset.EndpointsTrafficSet {
+		return randomTrafficSet(r, randomStrictTransportSet)
+	}, toBDD), lawsIterations)
+	settest.Check(t, settest.Variants(func(r *rand.Rand) *netset.EndpointsTrafficSet {
+		return randomTrafficSet(r, randomMixedTransportSet)
+	}, toBDD), lawsIterations)
+}
+
+func FuzzIPBlockLaws(f *testing.F) {
+	settest.FuzzUniversal(f, randomIPBlock)
+}
+
+func FuzzTransportSetLaws(f *testing.F) {
+	settest.FuzzUniversal(f, randomTransportSet)
+}
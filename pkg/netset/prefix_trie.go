@@ -0,0 +1,257 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/np-guard/models/pkg/interval"
+)
+
+// PrefixEntry is a CIDR stored in a PrefixTrie, with its value
+type PrefixEntry[V any] struct {
+	Prefix *IPBlock
+	Value  V
+}
+
+// PrefixTrie maps CIDRs to values of type V. It is implemented as a path-compressed binary (patricia) trie,
+// so longest-prefix match, and finding all covering or contained prefixes, take time linear in the prefix length.
+type PrefixTrie[V any] struct {
+	root *trieNode[V]
+	size int
+}
+
+type trieNode[V any] struct {
+	addr     uint32 // network address, with all host bits set to zero
+	length   int    // prefix length
+	value    V
+	hasValue bool // false for internal branching nodes
+	children [2]*trieNode[V]
+}
+
+// NewPrefixTrie returns an empty PrefixTrie
+func NewPrefixTrie[V any]() *PrefixTrie[V] {
+	return &PrefixTrie[V]{}
+}
+
+// prefixMask returns the network mask of the given prefix length
+func prefixMask(length int) uint32 {
+	return ^uint32(0) << (maxIPv4Bits - length)
+}
+
+// bitAt returns the i'th most significant bit of addr
+func bitAt(addr uint32, i int) int {
+	return int(addr>>(maxIPv4Bits-1-i)) & 1
+}
+
+// contains returns true if the prefix (addr, length) is contained in this node's prefix
+func (n *trieNode[V]) contains(addr uint32, length int) bool {
+	return n.length <= length && addr&prefixMask(n.length) == n.addr
+}
+
+func (n *trieNode[V]) prefix() *IPBlock {
+	start := int64(n.addr)
+	end := int64(n.addr | ^prefixMask(n.length))
+	return &IPBlock{ipRange: interval.New(start, end).ToSet()}
+}
+
+// cidrPrefix returns the network address and prefix length of an IPBlock that is exactly one CIDR
+func cidrPrefix(b *IPBlock) (addr uint32, length int, err error) {
+	if b.ipRange.NumIntervals() != 1 {
+		return 0, 0, fmt.Errorf("%s is not a single CIDR", b.String())
+	}
+	start, end := b.ipRange.Min(), b.ipRange.Max()
+	size := uint64(end - start + 1)
+	if size&(size-1) != 0 || uint64(start)&(size-1) != 0 {
+		return 0, 0, fmt.Errorf("%s is not a single CIDR", b.String())
+	}
+	//nolint:gosec // start is a valid IPv4 address
+	return uint32(start), maxIPv4Bits - bits.TrailingZeros64(size), nil
+}
+
+// Len returns the number of prefixes in the trie
+func (t *PrefixTrie[V]) Len() int {
+	return t.size
+}
+
+// Insert maps the CIDR to v, replacing its previous value (if any).
+// It returns an error if cidr is not exactly one CIDR.
+func (t *PrefixTrie[V]) Insert(cidr *IPBlock, v V) error {
+	addr, length, err := cidrPrefix(cidr)
+	if err != nil {
+		return err
+	}
+	t.insert(addr, length, v)
+	return nil
+}
+
+// InsertBlock maps all the CIDRs composing b (see IPBlock.SplitToCidrs) to v
+func (t *PrefixTrie[V]) InsertBlock(b *IPBlock, v V) {
+	for _, cidr := range b.SplitToCidrs() {
+		addr, length, _ := cidrPrefix(cidr)
+		t.insert(addr, length, v)
+	}
+}
+
+func (t *PrefixTrie[V]) insert(addr uint32, length int, v V) {
+	leaf := &trieNode[V]{addr: addr, length: length, value: v, hasValue: true}
+	p := &t.root
+	for {
+		n := *p
+		if n == nil {
+			*p = leaf
+			t.size++
+			return
+		}
+		common := min(bits.LeadingZeros32(n.addr^addr), n.length, length)
+		switch {
+		case common == n.length && common == length:
+			if !n.hasValue {
+				t.size++
+			}
+			n.value, n.hasValue = v, true
+			return
+		case common == n.length:
+			p = &n.children[bitAt(addr, n.length)]
+			continue
+		case common == length:
+			leaf.children[bitAt(n.addr, length)] = n
+			*p = leaf
+		default:
+			branch := &trieNode[V]{addr: addr & prefixMask(common), length: common}
+			branch.children[bitAt(addr, common)] = leaf
+			branch.children[bitAt(n.addr, common)] = n
+			*p = branch
+		}
+		t.size++
+		return
+	}
+}
+
+// Delete removes the CIDR from the trie. It returns true if the CIDR was in the trie.
+func (t *PrefixTrie[V]) Delete(cidr *IPBlock) bool {
+	addr, length, err := cidrPrefix(cidr)
+	if err != nil {
+		return false
+	}
+	var deleted bool
+	t.root, deleted = t.root.delete(addr, length)
+	if deleted {
+		t.size--
+	}
+	return deleted
+}
+
+// delete removes the prefix from the subtree rooted at n, and returns the new root of the subtree
+func (n *trieNode[V]) delete(addr uint32, length int) (*trieNode[V], bool) {
+	if n == nil || !n.contains(addr, length) {
+		return n, false
+	}
+	deleted := false
+	if n.length == length {
+		if !n.hasValue {
+			return n, false
+		}
+		var zero V
+		n.value, n.hasValue, deleted = zero, false, true
+	} else {
+		i := bitAt(addr, n.length)
+		n.children[i], deleted = n.children[i].delete(addr, length)
+	}
+	if n.hasValue {
+		return n, deleted
+	}
+	// compress internal nodes with less than two children
+	switch {
+	case n.children[0] == nil:
+		return n.children[1], deleted
+	case n.children[1] == nil:
+		return n.children[0], deleted
+	}
+	return n, deleted
+}
+
+// Get returns the value of exactly the given CIDR, and whether it is in the trie
+func (t *PrefixTrie[V]) Get(cidr *IPBlock) (res V, ok bool) {
+	addr, length, err := cidrPrefix(cidr)
+	if err != nil {
+		return res, false
+	}
+	for n := t.root; n != nil && n.contains(addr, length); n = n.children[bitAt(addr, n.length)] {
+		if n.length == length {
+			return n.value, n.hasValue
+		}
+	}
+	return res, false
+}
+
+// Covering returns all the prefixes in the trie that contain b (including b itself), from the shortest to the longest.
+// b should be a single CIDR or IP address; otherwise the result is empty.
+func (t *PrefixTrie[V]) Covering(b *IPBlock) []PrefixEntry[V] {
+	addr, length, err := cidrPrefix(b)
+	if err != nil {
+		return nil
+	}
+	var res []PrefixEntry[V]
+	for n := t.root; n != nil && n.contains(addr, length); {
+		if n.hasValue {
+			res = append(res, PrefixEntry[V]{Prefix: n.prefix(), Value: n.value})
+		}
+		if n.length == length {
+			break
+		}
+		n = n.children[bitAt(addr, n.length)]
+	}
+	return res
+}
+
+// LongestPrefixMatch returns the longest prefix in the trie that contains b, and whether such a prefix exists.
+// b should be a single CIDR or IP address; otherwise there is no match.
+func (t *PrefixTrie[V]) LongestPrefixMatch(b *IPBlock) (PrefixEntry[V], bool) {
+	covering := t.Covering(b)
+	if len(covering) == 0 {
+		return PrefixEntry[V]{}, false
+	}
+	return covering[len(covering)-1], true
+}
+
+// Contained returns all the prefixes in the trie that are contained in b (including b itself), in address order.
+// b should be a single CIDR; otherwise the result is empty.
+func (t *PrefixTrie[V]) Contained(b *IPBlock) []PrefixEntry[V] {
+	addr, length, err := cidrPrefix(b)
+	if err != nil {
+		return nil
+	}
+	n := t.root
+	for n != nil && n.length < length {
+		if !n.contains(addr, length) {
+			return nil
+		}
+		n = n.children[bitAt(addr, n.length)]
+	}
+	if n == nil || n.addr&prefixMask(length) != addr {
+		return nil
+	}
+	return n.entries(nil)
+}
+
+// Entries returns all the prefixes in the trie in address order, where a prefix precedes the prefixes it contains
+func (t *PrefixTrie[V]) Entries() []PrefixEntry[V] {
+	return t.root.entries(nil)
+}
+
+func (n *trieNode[V]) entries(res []PrefixEntry[V]) []PrefixEntry[V] {
+	if n == nil {
+		return res
+	}
+	if n.hasValue {
+		res = append(res, PrefixEntry[V]{Prefix: n.prefix(), Value: n.value})
+	}
+	res = n.children[0].entries(res)
+	return n.children[1].entries(res)
+}
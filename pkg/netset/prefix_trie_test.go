@@ -0,0 +1,96 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func cidrOf(t *testing.T, s string) *netset.IPBlock {
+	t.Helper()
+	res, err := netset.IPBlockFromCidrOrAddress(s)
+	require.Nil(t, err)
+	return res
+}
+
+func entriesStrings[V any](entries []netset.PrefixEntry[V]) []string {
+	res := make([]string, len(entries))
+	for i, e := range entries {
+		res[i] = e.Prefix.String()
+	}
+	return res
+}
+
+func TestPrefixTrieLongestPrefixMatch(t *testing.T) {
+	trie := netset.NewPrefixTrie[string]()
+	require.Nil(t, trie.Insert(cidrOf(t, "10.0.0.0/8"), "vpc"))
+	require.Nil(t, trie.Insert(cidrOf(t, "10.240.10.0/24"), "subnet1"))
+	require.Nil(t, trie.Insert(cidrOf(t, "10.240.20.0/24"), "subnet2"))
+	require.Nil(t, trie.Insert(cidrOf(t, "0.0.0.0/0"), "public"))
+	require.Equal(t, 4, trie.Len())
+
+	match, ok := trie.LongestPrefixMatch(cidrOf(t, "10.240.10.7"))
+	require.True(t, ok)
+	require.Equal(t, "subnet1", match.Value)
+	require.Equal(t, "10.240.10.0/24", match.Prefix.String())
+
+	match, ok = trie.LongestPrefixMatch(cidrOf(t, "10.240.30.7"))
+	require.True(t, ok)
+	require.Equal(t, "vpc", match.Value)
+
+	match, ok = trie.LongestPrefixMatch(cidrOf(t, "8.8.8.8"))
+	require.True(t, ok)
+	require.Equal(t, "public", match.Value)
+
+	require.Equal(t, []string{"0.0.0.0/0", "10.0.0.0/8", "10.240.10.0/24"},
+		entriesStrings(trie.Covering(cidrOf(t, "10.240.10.0/28"))))
+	require.Equal(t, []string{"10.240.10.0/24", "10.240.20.0/24"},
+		entriesStrings(trie.Contained(cidrOf(t, "10.240.0.0/16"))))
+	require.Empty(t, trie.Contained(cidrOf(t, "10.241.0.0/16")))
+
+	val, ok := trie.Get(cidrOf(t, "10.0.0.0/8"))
+	require.True(t, ok)
+	require.Equal(t, "vpc", val)
+	_, ok = trie.Get(cidrOf(t, "10.0.0.0/9"))
+	require.False(t, ok)
+}
+
+func TestPrefixTrieInsertAndDelete(t *testing.T) {
+	trie := netset.NewPrefixTrie[int]()
+	require.NotNil(t, trie.Insert(cidrOf(t, "10.0.0.0/8").Union(cidrOf(t, "12.0.0.0/8")), 0))
+
+	block, err := netset.IPBlockFromIPRangeStr("10.0.0.0-10.0.0.5")
+	require.Nil(t, err)
+	trie.InsertBlock(block, 1)
+	require.Nil(t, trie.Insert(cidrOf(t, "10.0.0.0/24"), 2))
+	require.Equal(t, []string{"10.0.0.0/24", "10.0.0.0/30", "10.0.0.4/31"}, entriesStrings(trie.Entries()))
+
+	// replacing a value does not change the size
+	require.Nil(t, trie.Insert(cidrOf(t, "10.0.0.0/24"), 3))
+	require.Equal(t, 3, trie.Len())
+
+	require.True(t, trie.Delete(cidrOf(t, "10.0.0.0/30")))
+	require.False(t, trie.Delete(cidrOf(t, "10.0.0.0/30")))
+	require.False(t, trie.Delete(cidrOf(t, "10.0.0.0/29")))
+	require.Equal(t, 2, trie.Len())
+	require.Equal(t, []string{"10.0.0.0/24", "10.0.0.4/31"}, entriesStrings(trie.Entries()))
+
+	match, ok := trie.LongestPrefixMatch(cidrOf(t, "10.0.0.1"))
+	require.True(t, ok)
+	require.Equal(t, 3, match.Value)
+
+	require.True(t, trie.Delete(cidrOf(t, "10.0.0.0/24")))
+	require.True(t, trie.Delete(cidrOf(t, "10.0.0.4/31")))
+	require.Equal(t, 0, trie.Len())
+	require.Empty(t, trie.Entries())
+	_, ok = trie.LongestPrefixMatch(cidrOf(t, "10.0.0.4"))
+	require.False(t, ok)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/np-guard/models/pkg/interval"
+	"github.com/np-guard/models/pkg/netp"
+)
+
+// ReportFormat is an output format of RenderReport
+type ReportFormat string
+
+const (
+	ReportCSV       ReportFormat = "csv"
+	ReportMarkdown  ReportFormat = "md"
+	ReportJSONLines ReportFormat = "jsonl"
+	ReportText      ReportFormat = "txt"
+)
+
+// IPDisplay controls how IP addresses are displayed in a report
+type IPDisplay int
+
+const (
+	// IPDisplayCIDR displays IP addresses as a list of CIDRs
+	IPDisplayCIDR IPDisplay = iota
+	// IPDisplayRange displays each contiguous range of IP addresses as a single CIDR, if possible, or as a start-end range
+	IPDisplayRange
+)
+
+// anyValue is the report value of a column that is not restricted (e.g., all ports)
+const anyValue = "any"
+
+// protocolAll is the report protocol of rows that allow all TCP, UDP and ICMP connections
+const protocolAll = "ALL"
+
+// ReportOptions controls the rows of a report
+type ReportOptions struct {
+	// IPDisplay controls the display of IP addresses. Relevant only for EndpointsTrafficSet.
+	IPDisplay IPDisplay
+	// EndpointNames maps endpoint IDs to display names. Relevant only for DiscreteEndpointsTrafficSet;
+	// endpoints without a name are displayed by their ID.
+	EndpointNames map[int64]string
+}
+
+// ReportRow is a single row of a report, describing the connections over a single protocol between a set of
+// sources and a set of destinations. Columns that are irrelevant to the protocol are empty.
+type ReportRow struct {
+	Src      string `json:"src"`
+	Dst      string `json:"dst"`
+	Protocol string `json:"protocol"`
+	SrcPorts string `json:"src_ports,omitempty"`
+	DstPorts string `json:"dst_ports,omitempty"`
+	ICMPType string `json:"icmp_type,omitempty"`
+	ICMPCode string `json:"icmp_code,omitempty"`
+}
+
+var reportHeader = []string{"src", "dst", "protocol", "src_ports", "dst_ports", "icmp_type", "icmp_code"}
+
+func (r *ReportRow) columns() []string {
+	return []string{r.Src, r.Dst, r.Protocol, r.SrcPorts, r.DstPorts, r.ICMPType, r.ICMPCode}
+}
+
+// Report returns the rows of a report of this set: a row for each protocol of each cube (see Partitions)
+func (c *EndpointsTrafficSet) Report(opts ReportOptions) []ReportRow {
+	var res []ReportRow
+	for _, cube := range c.Partitions() {
+		res = append(res, transportRows(ipsLabel(cube.S1, opts.IPDisplay), ipsLabel(cube.S2, opts.IPDisplay), cube.S3)...)
+	}
+	return res
+}
+
+// Report returns the rows of a report of this set: a row for each protocol of each cube (see Partitions)
+func (c *DiscreteEndpointsTrafficSet) Report(opts ReportOptions) []ReportRow {
+	var res []ReportRow
+	label := endpointsLabeler(opts.EndpointNames)
+	for _, cube := range c.Partitions() {
+		src, dst := label(cube.S1), label(cube.S2)
+		res = append(res, transportRows(src, dst, cube.S3)...)
+	}
+	return res
+}
+
+func ipsLabel(b *IPBlock, display IPDisplay) string {
+	if display == IPDisplayRange {
+		return strings.Join(b.ListToPrint(), commaSeparator)
+	}
+	return b.String()
+}
+
+// valuesLabel returns the report value of a set of ports, types or codes, given the set of all possible values
+func valuesLabel(values, all *interval.CanonicalSet) string {
+	if values.Equal(all) {
+		return anyValue
+	}
+	return values.String()
+}
+
+// icmpTypesLabel returns the report value of a set of ICMP types, naming a single type where possible
+func icmpTypesLabel(types *TypeSet) string {
+	if name, ok := netp.ICMPTypeName(int(types.Min())); ok && types.IsSingleNumber() {
+		return name
+	}
+	return valuesLabel(types, AllICMPTypes())
+}
+
+// icmpCodesLabel returns the report value of a set of codes of the given ICMP types, naming a single code where possible
+func icmpCodesLabel(types *TypeSet, codes *CodeSet) string {
+	if name, ok := netp.ICMPCodeName(int(types.Min()), int(codes.Min())); ok && types.IsSingleNumber() && codes.IsSingleNumber() {
+		return name
+	}
+	return valuesLabel(codes, AllICMPCodes())
+}
+
+// transportRows returns the report rows of the connections conn from src to dst
+func transportRows(src, dst string, conn *TransportSet) []ReportRow {
+	if conn.IsAll() {
+		return []ReportRow{{Src: src, Dst: dst, Protocol: protocolAll}}
+	}
+	var res []ReportRow
+	for _, cube := range conn.TCPUDPSet().Partitions() {
+		for _, code := range cube.S1.Elements() {
+			protocol := netp.ProtocolStringTCP
+			if code == UDPCode {
+				protocol = netp.ProtocolStringUDP
+			}
+			res = append(res, ReportRow{Src: src, Dst: dst, Protocol: string(protocol),
+				SrcPorts: valuesLabel(cube.S2, AllPorts()), DstPorts: valuesLabel(cube.S3, AllPorts())})
+		}
+	}
+	for _, cube := range conn.ICMPSet().Partitions() {
+		res = append(res, ReportRow{Src: src, Dst: dst, Protocol: string(netp.ProtocolStringICMP),
+			ICMPType: icmpTypesLabel(cube.Left), ICMPCode: icmpCodesLabel(cube.Left, cube.Right)})
+	}
+	return res
+}
+
+// RenderReport writes the report rows to w in the given format
+func RenderReport(w io.Writer, rows []ReportRow, format ReportFormat) error {
+	switch format {
+	case ReportCSV:
+		return renderCSV(w, rows)
+	case ReportMarkdown:
+		return renderMarkdown(w, rows)
+	case ReportJSONLines:
+		return renderJSONLines(w, rows)
+	case ReportText:
+		return renderText(w, rows)
+	}
+	return fmt.Errorf("unsupported report format %q", format)
+}
+
+func renderCSV(w io.Writer, rows []ReportRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(reportHeader); err != nil {
+		return err
+	}
+	for i := range rows {
+		if err := writer.Write(rows[i].columns()); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func markdownLine(columns []string) string {
+	escaped := make([]string, len(columns))
+	for i, col := range columns {
+		escaped[i] = strings.ReplaceAll(col, "|", `\|`)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |\n"
+}
+
+func renderMarkdown(w io.Writer, rows []ReportRow) error {
+	separator := make([]string, len(reportHeader))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	var sb strings.Builder
+	sb.WriteString(markdownLine(reportHeader))
+	sb.WriteString(markdownLine(separator))
+	for i := range rows {
+		sb.WriteString(markdownLine(rows[i].columns()))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func renderJSONLines(w io.Writer, rows []ReportRow) error {
+	encoder := json.NewEncoder(w)
+	for i := range rows {
+		if err := encoder.Encode(rows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderText(w io.Writer, rows []ReportRow) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(reportHeader, "\t"))
+	for i := range rows {
+		fmt.Fprintln(writer, strings.Join(rows[i].columns(), "\t"))
+	}
+	return writer.Flush()
+}
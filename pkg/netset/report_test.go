@@ -0,0 +1,71 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/interval"
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func render(t *testing.T, rows []netset.ReportRow, format netset.ReportFormat) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.Nil(t, netset.RenderReport(&buf, rows, format))
+	return buf.String()
+}
+
+func TestEndpointsTrafficSetReport(t *testing.T) {
+	src, _ := netset.IPBlockFromIPRangeStr("10.240.10.0-10.240.10.5")
+	dst, _ := netset.IPBlockFromCidr("10.240.20.0/24")
+	conns := netset.NewEndpointsTrafficSet(src, dst, netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22).Union(
+		netset.NewICMPTransport(netp.Echo, netp.Echo, 0, 0)))
+
+	rows := conns.Report(netset.ReportOptions{})
+	require.Equal(t, []netset.ReportRow{
+		{Src: "10.240.10.0/30, 10.240.10.4/31", Dst: "10.240.20.0/24", Protocol: "TCP", SrcPorts: "any", DstPorts: "22"},
+		{Src: "10.240.10.0/30, 10.240.10.4/31", Dst: "10.240.20.0/24", Protocol: "ICMP", ICMPType: "echo-request", ICMPCode: "0"},
+	}, rows)
+
+	rows = conns.Report(netset.ReportOptions{IPDisplay: netset.IPDisplayRange})
+	require.Equal(t, "10.240.10.0-10.240.10.5", rows[0].Src)
+
+	require.Equal(t, "src,dst,protocol,src_ports,dst_ports,icmp_type,icmp_code\n"+
+		"10.240.10.0-10.240.10.5,10.240.20.0/24,TCP,any,22,,\n"+
+		"10.240.10.0-10.240.10.5,10.240.20.0/24,ICMP,,,echo-request,0\n", render(t, rows, netset.ReportCSV))
+
+	require.Equal(t, "| src | dst | protocol | src_ports | dst_ports | icmp_type | icmp_code |\n"+
+		"| --- | --- | --- | --- | --- | --- | --- |\n"+
+		"| 10.240.10.0-10.240.10.5 | 10.240.20.0/24 | TCP | any | 22 |  |  |\n"+
+		"| 10.240.10.0-10.240.10.5 | 10.240.20.0/24 | ICMP |  |  | echo-request | 0 |\n", render(t, rows, netset.ReportMarkdown))
+
+	require.Equal(t, `{"src":"10.240.10.0-10.240.10.5","dst":"10.240.20.0/24","protocol":"TCP","src_ports":"any","dst_ports":"22"}`+"\n"+
+		`{"src":"10.240.10.0-10.240.10.5","dst":"10.240.20.0/24","protocol":"ICMP","icmp_type":"echo-request","icmp_code":"0"}`+"\n",
+		render(t, rows, netset.ReportJSONLines))
+
+	require.Equal(t, "src                      dst             protocol  src_ports  dst_ports  icmp_type     icmp_code\n"+
+		"10.240.10.0-10.240.10.5  10.240.20.0/24  TCP       any        22                       \n"+
+		"10.240.10.0-10.240.10.5  10.240.20.0/24  ICMP                            echo-request  0\n",
+		render(t, rows, netset.ReportText))
+
+	var buf bytes.Buffer
+	require.NotNil(t, netset.RenderReport(&buf, rows, "xml"))
+}
+
+func TestDiscreteEndpointsTrafficSetReport(t *testing.T) {
+	conns := netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 1).ToSet(), interval.New(2, 2).ToSet(), netset.AllTransports())
+	names := map[int64]string{0: "vsi-a", 1: "vsi-b"}
+
+	require.Equal(t, []netset.ReportRow{{Src: "vsi-a, vsi-b", Dst: "2", Protocol: "ALL"}},
+		conns.Report(netset.ReportOptions{EndpointNames: names}))
+	require.Equal(t, []netset.ReportRow{{Src: "0-1", Dst: "2", Protocol: "ALL"}}, conns.Report(netset.ReportOptions{}))
+}
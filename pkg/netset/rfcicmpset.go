@@ -7,16 +7,18 @@ SPDX-License-Identifier: Apache-2.0
 package netset
 
 import (
-	"fmt"
 	"log"
-	"sort"
+	"math/big"
 	"strings"
 
+	"github.com/np-guard/models/pkg/ds"
 	"github.com/np-guard/models/pkg/netp"
 )
 
-// RFCICMPSet is a set of _valid_ (by RFC) ICMP values, encoded as a bitset
-type RFCICMPSet uint32
+// RFCICMPSet is a set of _valid_ (by RFC) ICMP values, encoded as a ds.BitSet of ICMPEnumeration
+type RFCICMPSet struct {
+	bits *ds.BitSet[netp.ICMP]
+}
 
 // Encoding for ICMP types and codes, enumerating the possible pairs of values.
 // For example:
@@ -69,75 +71,129 @@ func encode(t, code int) int {
 	}
 }
 
-//lint:ignore U1000 should be used in the future
-func decode(encodedCode int) (netp.ICMP, error) {
-	t := encodedCode
-	switch {
-	case encodedCode < encodedRedirect:
-		t = encodedDestinationUnreachable
-	case encodedCode < encodedTimeExceeded:
-		t = encodedRedirect
-	case encodedCode < netp.ParameterProblem:
-		t = encodedTimeExceeded
-	case encodedCode == encodedEcho:
-		t = netp.Echo
-	case encodedCode == encodedEchoReply:
-		t = netp.EchoReply
-	case encodedCode == encodedSourceQuench:
-		t = netp.SourceQuench
+// decodeTypeCode returns the ICMP type and code of the given encoding
+func decodeTypeCode(encodedCode int) (t, code int) {
+	for _, t = range netp.Types() {
+		if code = encodedCode - encode(t, 0); code >= 0 && code <= netp.MaxCode(t) {
+			return t, code
+		}
+	}
+	log.Panicf("Invalid ICMP encoding %v", encodedCode)
+	return 0, 0
+}
+
+// decode returns the ICMP value of the given encoding
+func decode(encodedCode int) netp.ICMP {
+	t, code := decodeTypeCode(encodedCode)
+	icmp, err := netp.NewICMP(&netp.ICMPTypeCode{Type: t, Code: &code})
+	if err != nil {
+		log.Panicf("decoding failed for type %v, code %v", t, code)
+	}
+	return icmp
+}
+
+// encodeICMP returns the encoding of an ICMP value with a single code, and false for other ICMP values
+func encodeICMP(icmp netp.ICMP) (int, bool) {
+	if icmp.TypeCode == nil || netp.ValidateICMP(icmp.TypeCode) != nil {
+		return 0, false
+	}
+	if icmp.TypeCode.Code != nil {
+		return encode(icmp.TypeCode.Type, *icmp.TypeCode.Code), true
+	}
+	if netp.HasSingleCode(icmp.TypeCode.Type) {
+		return encode(icmp.TypeCode.Type, 0), true
+	}
+	return 0, false
+}
+
+var icmpEnumeration = ds.NewEnumerationFunc(last+1, encodeICMP, decode)
+
+// ICMPEnumeration returns the enumeration of the RFC-valid ICMP values with a single code, in the order of their encoding
+func ICMPEnumeration() *ds.Enumeration[netp.ICMP] {
+	return icmpEnumeration
+}
+
+// BitSet returns the set as a ds.BitSet of ICMPEnumeration
+func (s *RFCICMPSet) BitSet() *ds.BitSet[netp.ICMP] {
+	return s.bits.Copy()
+}
+
+// ICMPSetFromRFCICMPSet returns the ICMPSet of the ICMP type and code pairs in s
+func ICMPSetFromRFCICMPSet(s *RFCICMPSet) *ICMPSet {
+	res := EmptyICMPSet()
+	for _, i := range s.bits.Indices() {
+		t, code := decodeTypeCode(i)
+		res = res.Union(NewICMPSet(int64(t), int64(t), int64(code), int64(code)))
+	}
+	return res
+}
+
+// RFCICMPSetFromICMPSet returns the RFCICMPSet of the ICMP type and code pairs in c, ignoring pairs that are not valid by RFC
+func RFCICMPSetFromICMPSet(c *ICMPSet) *RFCICMPSet {
+	var indices []int
+	for i := 0; i <= last; i++ {
+		t, code := decodeTypeCode(i)
+		if NewICMPSet(int64(t), int64(t), int64(code), int64(code)).IsSubset(c) {
+			indices = append(indices, i)
+		}
 	}
-	code := encodedCode - t
-	return netp.NewICMP(&netp.ICMPTypeCode{Type: t, Code: &code})
+	return fromIndices(indices...)
+}
+
+// RFCICMPSetFromBitSet returns the RFCICMPSet of a ds.BitSet of ICMPEnumeration
+func RFCICMPSetFromBitSet(b *ds.BitSet[netp.ICMP]) *RFCICMPSet {
+	return fromIndices(b.Indices()...)
 }
 
 func (s *RFCICMPSet) IsSubset(other *RFCICMPSet) bool {
-	return ((*s) | (*other)) == (*other)
+	return s.bits.IsSubset(other.bits)
 }
 
 func (s *RFCICMPSet) Union(other *RFCICMPSet) *RFCICMPSet {
-	var res = (*s) | (*other)
-	return &res
+	return &RFCICMPSet{bits: s.bits.Union(other.bits)}
 }
 
 func (s *RFCICMPSet) Intersect(other *RFCICMPSet) *RFCICMPSet {
-	var res = (*s) & (*other)
-	return &res
+	return &RFCICMPSet{bits: s.bits.Intersect(other.bits)}
 }
 
 func (s *RFCICMPSet) Subtract(other *RFCICMPSet) *RFCICMPSet {
-	var res = (*s) & ^(*other)
-	return &res
+	return &RFCICMPSet{bits: s.bits.Subtract(other.bits)}
+}
+
+// Compare returns -1 if s<other, 1 if s>other, 0 o.w.
+func (s *RFCICMPSet) Compare(other *RFCICMPSet) int {
+	return s.bits.Compare(other.bits)
 }
 
 func (s *RFCICMPSet) Equal(other *RFCICMPSet) bool {
-	return *s == *other
+	return s.bits.Equal(other.bits)
 }
 
 func (s *RFCICMPSet) Copy() *RFCICMPSet {
-	var res = *s
-	return &res
+	return &RFCICMPSet{bits: s.bits.Copy()}
 }
 
 func (s *RFCICMPSet) Hash() int {
-	return int(*s)
+	return s.bits.Hash()
+}
+
+// BigSize returns the exact number of (type, code) pairs in the set
+func (s *RFCICMPSet) BigSize() *big.Int {
+	return s.bits.BigSize()
 }
 
 func (s *RFCICMPSet) Size() int {
-	res := 0
-	for i := 0; i <= last; i++ {
-		if s.Contains(i) {
-			res++
-		}
-	}
-	return res
+	return s.bits.Size()
 }
 
 func (s *RFCICMPSet) IsEmpty() bool {
-	return s.Equal(EmptyICMPSetStrict())
+	return s.bits.IsEmpty()
 }
 
+// Contains returns true if the ICMP value of the given encoding is in the set
 func (s *RFCICMPSet) Contains(i int) bool {
-	return ((1 << i) & (*s)) != 0
+	return s.bits.ContainsIndex(i)
 }
 
 // collect returns a list of ICMP values for a given type, collecting into a single ICMP value with nil Code if all codes are present.
@@ -162,8 +218,7 @@ func (s *RFCICMPSet) collect(old int) []netp.ICMP {
 // if all codes for a given type are present, it adds a single ICMP value with nil Code.
 // If all ICMP values are present, a single ICMP value with nil TypeCode is returned.
 func (s *RFCICMPSet) Partitions() []netp.ICMP {
-	all := RFCICMPSet(allCodes)
-	if all.IsSubset(s) {
+	if s.IsAll() {
 		return []netp.ICMP{{TypeCode: nil}}
 	}
 	var res []netp.ICMP
@@ -173,33 +228,30 @@ func (s *RFCICMPSet) Partitions() []netp.ICMP {
 	return res
 }
 
-func fromIndex(i int) *RFCICMPSet {
-	var res RFCICMPSet = 1 << i
-	return &res
+func fromIndices(indices ...int) *RFCICMPSet {
+	return &RFCICMPSet{bits: ds.BitSetFromIndices(icmpEnumeration, indices...)}
 }
 
 func (s *RFCICMPSet) IsAll() bool {
-	return s.Equal(AllICMPSetStrict())
+	return s.bits.IsAll()
 }
 
-// constants for sets of ICMP codes, grouped by types.
-// For example, allDestinationUnreachable is the set of all ICMP codes for DestinationUnreachable type.
-const (
-	allDestinationUnreachable = 0b00000000000000111111
-	allRedirect               = 0b00000000001111000000
-	allTimeExceeded           = 0b00000000110000000000
-	allOther                  = 0b11111111000000000000
-	allCodes                  = allDestinationUnreachable | allRedirect | allTimeExceeded | allOther
-)
+// Universe returns the set of all RFC-valid ICMP type and code pairs
+func (s *RFCICMPSet) Universe() *RFCICMPSet {
+	return AllICMPSetStrict()
+}
+
+// Complement returns the RFC-valid ICMP type and code pairs not in this set
+func (s *RFCICMPSet) Complement() *RFCICMPSet {
+	return &RFCICMPSet{bits: s.bits.Complement()}
+}
 
 func EmptyICMPSetStrict() *RFCICMPSet {
-	var res RFCICMPSet = 0
-	return &res
+	return fromIndices()
 }
 
 func AllICMPSetStrict() *RFCICMPSet {
-	res := RFCICMPSet(allCodes)
-	return &res
+	return &RFCICMPSet{bits: ds.FullBitSet(icmpEnumeration)}
 }
 
 func NewICMPSetStrict(t netp.ICMP) *RFCICMPSet {
@@ -207,36 +259,18 @@ func NewICMPSetStrict(t netp.ICMP) *RFCICMPSet {
 		return AllICMPSetStrict()
 	}
 	if t.TypeCode.Code != nil {
-		return fromIndex(encode(t.TypeCode.Type, *t.TypeCode.Code))
+		return fromIndices(encode(t.TypeCode.Type, *t.TypeCode.Code))
 	}
-	var res RFCICMPSet
-	switch t.TypeCode.Type {
-	case netp.DestinationUnreachable:
-		res = allDestinationUnreachable
-	case netp.Redirect:
-		res = allRedirect
-	case netp.TimeExceeded:
-		res = allTimeExceeded
-	default:
-		res = *fromIndex(encode(t.TypeCode.Type, 0))
-	}
-	return &res
-}
-
-func getRFCICMPCubeStr(cube netp.ICMP) string {
-	tc := cube.ICMPTypeCode()
-	if tc == nil {
-		return ""
-	}
-	if tc.Code == nil {
-		if netp.HasSingleCode(tc.Type) {
-			return fmt.Sprintf("icmp-type: %d icmp-code: 0", tc.Type)
-		}
-		return fmt.Sprintf("icmp-type: %d", tc.Type)
+	indices := make([]int, netp.MaxCode(t.TypeCode.Type)+1)
+	for code := range indices {
+		indices[code] = encode(t.TypeCode.Type, code)
 	}
-	return fmt.Sprintf("icmp-type: %d icmp-code: %d", tc.Type, *tc.Code)
+	return fromIndices(indices...)
 }
 
+// String returns the symbolic form of the set: "ICMP" followed by a comma separated list of types and type/code pairs,
+// named as in RFC 792 (e.g., "ICMP echo-request,dest-unreachable/port-unreachable").
+// Types for which all codes are in the set are written without codes.
 func (s *RFCICMPSet) String() string {
 	if s.IsEmpty() {
 		return ""
@@ -244,13 +278,33 @@ func (s *RFCICMPSet) String() string {
 	cubes := s.Partitions()
 	var resStrings = make([]string, len(cubes))
 	for i, cube := range cubes {
-		resStrings[i] = getRFCICMPCubeStr(cube)
+		resStrings[i] = cube.String()
 	}
-	sort.Strings(resStrings)
 	str := string(netp.ProtocolStringICMP)
-	last := strings.Join(resStrings, semicolon)
-	if last != "" {
-		str += " " + last
+	if !s.IsAll() {
+		str += " " + strings.Join(resStrings, comma)
 	}
 	return str
 }
+
+// ParseICMPSetStrict parses an RFCICMPSet, in the form returned by String. The "ICMP" prefix is optional.
+// Types and codes may be given as symbolic names or as numbers; for example, "echo-request,dest-unreachable/3,13".
+func ParseICMPSetStrict(s string) (*RFCICMPSet, error) {
+	s = strings.TrimSpace(s)
+	if s == string(netp.ProtocolStringICMP) {
+		return AllICMPSetStrict(), nil
+	}
+	s = strings.TrimPrefix(s, string(netp.ProtocolStringICMP)+" ")
+	res := EmptyICMPSetStrict()
+	if s == "" {
+		return res, nil
+	}
+	for _, icmpStr := range strings.Split(s, comma) {
+		icmp, err := netp.ParseICMP(icmpStr)
+		if err != nil {
+			return nil, err
+		}
+		res = res.Union(NewICMPSetStrict(icmp))
+	}
+	return res, nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/interval"
+	"github.com/np-guard/models/pkg/netp"
+)
+
+// this file defines the sampling of concrete elements from IP blocks, transport sets and traffic sets
+
+// Transport is a concrete transport: TCP or UDP with a source and destination port, or ICMP with a type and code
+type Transport struct {
+	Protocol netp.ProtocolString
+	// SrcPort and DstPort are set for TCP and UDP
+	SrcPort, DstPort int
+	// ICMPType and ICMPCode are set for ICMP
+	ICMPType, ICMPCode int
+}
+
+// String returns a string representation of the transport, e.g., "TCP 1234->443" or "ICMP echo-request/0"
+func (t Transport) String() string {
+	if t.Protocol == netp.ProtocolStringICMP {
+		code := t.ICMPCode
+		return fmt.Sprintf("%s %s", t.Protocol, netp.ICMP{TypeCode: &netp.ICMPTypeCode{Type: t.ICMPType, Code: &code}})
+	}
+	return fmt.Sprintf("%s %d->%d", t.Protocol, t.SrcPort, t.DstPort)
+}
+
+// Packet is a concrete connection: a source and destination IP address, and a transport
+type Packet struct {
+	Src, Dst string
+	Transport
+}
+
+// String returns a string representation of the packet, e.g., "10.0.0.1 -> 10.0.0.2 TCP 1234->443"
+func (p Packet) String() string {
+	return fmt.Sprintf("%s -> %s %s", p.Src, p.Dst, p.Transport)
+}
+
+// Sample returns an IP address of the block, chosen uniformly at random. It panics if the block is empty.
+func (b *IPBlock) Sample(r *rand.Rand) string {
+	return int64ToIP4(b.ipRange.Sample(r))
+}
+
+// Sample returns a transport of the set, chosen uniformly at random. It panics if the set is empty.
+func (t *TransportSet) Sample(r *rand.Rand) Transport {
+	return newTransportSampler(t).Sample(r)
+}
+
+// transportSampler samples the transports of a TransportSet, with the partitions of its TCP/UDP and ICMP sets
+type transportSampler struct {
+	protocols *ds.IndexSampler // the index of TCP/UDP (0) or ICMP (1), by their sizes
+	tcpudp    *ds.TripleSampler[*interval.CanonicalSet, *interval.CanonicalSet, *interval.CanonicalSet, int64, int64, int64]
+	icmp      *ds.ProductSampler[*interval.CanonicalSet, *interval.CanonicalSet, int64, int64]
+}
+
+func newTransportSampler(t *TransportSet) *transportSampler {
+	tcpudp, icmp := t.TCPUDPSet(), t.ICMPSet()
+	return &transportSampler{
+		protocols: ds.NewIndexSampler(tcpudp.BigSize(), icmp.BigSize()),
+		tcpudp:    ds.NewTripleSampler(tcpudp.props),
+		icmp:      ds.NewProductSampler(icmp.props),
+	}
+}
+
+func (s *transportSampler) BigSize() *big.Int {
+	return s.protocols.BigSize()
+}
+
+func (s *transportSampler) Sample(r *rand.Rand) Transport {
+	if s.protocols.Sample(r) == 0 {
+		ports := s.tcpudp.Sample(r)
+		protocol := netp.ProtocolStringTCP
+		if ports.S1 == UDPCode {
+			protocol = netp.ProtocolStringUDP
+		}
+		return Transport{Protocol: protocol, SrcPort: int(ports.S2), DstPort: int(ports.S3)}
+	}
+	typeCode := s.icmp.Sample(r)
+	return Transport{Protocol: netp.ProtocolStringICMP, ICMPType: int(typeCode.Left), ICMPCode: int(typeCode.Right)}
+}
+
+// Sample returns a packet of the set, chosen uniformly at random. It panics if the set is empty.
+// To sample many packets of the set, use a TrafficSampler.
+func (c *EndpointsTrafficSet) Sample(r *rand.Rand) Packet {
+	triple := ds.SampleTriple(r, c.props)
+	return Packet{Src: triple.S1, Dst: triple.S2, Transport: triple.S3}
+}
+
+// TrafficSampler samples the packets of an EndpointsTrafficSet uniformly, as EndpointsTrafficSet.Sample does.
+// It computes the cubes of the set and the sizes of their sets once, so that sampling many packets (e.g., by
+// ds.SampleN) does not recompute them.
+type TrafficSampler struct {
+	cubes      []ds.Triple[*IPBlock, *IPBlock, *TransportSet]
+	transports []*transportSampler
+	index      *ds.IndexSampler
+}
+
+// NewTrafficSampler returns a sampler of the packets of c
+func NewTrafficSampler(c *EndpointsTrafficSet) *TrafficSampler {
+	cubes := c.Partitions()
+	res := &TrafficSampler{cubes: cubes, transports: make([]*transportSampler, len(cubes))}
+	weights := make([]*big.Int, len(cubes))
+	for i, cube := range cubes {
+		res.transports[i] = newTransportSampler(cube.S3)
+		weights[i] = new(big.Int).Mul(cube.S1.BigSize(), cube.S2.BigSize())
+		weights[i].Mul(weights[i], res.transports[i].BigSize())
+	}
+	res.index = ds.NewIndexSampler(weights...)
+	return res
+}
+
+// BigSize returns the number of packets of the set
+func (s *TrafficSampler) BigSize() *big.Int {
+	return s.index.BigSize()
+}
+
+// Sample returns a packet of the set, chosen uniformly at random. It panics if the set is empty.
+func (s *TrafficSampler) Sample(r *rand.Rand) Packet {
+	i := s.index.Sample(r)
+	return Packet{Src: s.cubes[i].S1.Sample(r), Dst: s.cubes[i].S2.Sample(r), Transport: s.transports[i].Sample(r)}
+}
+
+var (
+	_ ds.Sampler[int64]     = &interval.CanonicalSet{}
+	_ ds.Sampler[string]    = &IPBlock{}
+	_ ds.Sampler[Transport] = &TransportSet{}
+	_ ds.Sampler[Packet]    = &EndpointsTrafficSet{}
+	_ ds.Sampler[Packet]    = &TrafficSampler{}
+)
@@ -0,0 +1,96 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+// packetSet returns the set holding only the given packet
+func packetSet(t *testing.T, p netset.Packet) *netset.EndpointsTrafficSet {
+	t.Helper()
+	src, err := netset.IPBlockFromIPAddress(p.Src)
+	require.NoError(t, err)
+	dst, err := netset.IPBlockFromIPAddress(p.Dst)
+	require.NoError(t, err)
+	var transport *netset.TransportSet
+	switch p.Protocol {
+	case netp.ProtocolStringICMP:
+		transport = netset.NewICMPTransport(int64(p.ICMPType), int64(p.ICMPType), int64(p.ICMPCode), int64(p.ICMPCode))
+	default:
+		transport = netset.NewTCPorUDPTransport(p.Protocol, int64(p.SrcPort), int64(p.SrcPort), int64(p.DstPort), int64(p.DstPort))
+	}
+	return netset.NewEndpointsTrafficSet(src, dst, transport)
+}
+
+func TestSampleTrafficSet(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	subnet, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	gateway, _ := netset.IPBlockFromIPAddress("10.240.0.1")
+	// 256 HTTPS connections from the subnet, and 256 ICMP echo requests from the gateway
+	conns := netset.NewEndpointsTrafficSet(subnet, gateway, netset.NewTCPTransport(443, 443, 443, 443)).Union(
+		netset.NewEndpointsTrafficSet(gateway, subnet, netset.NewICMPTransport(8, 8, 0, 0)))
+	require.Equal(t, 512, conns.Size())
+
+	for _, set := range []*netset.EndpointsTrafficSet{conns, conns.WithBackend(netset.BDDBackend)} {
+		icmp := 0
+		for range 2000 {
+			p := set.Sample(r)
+			require.True(t, packetSet(t, p).IsSubset(conns), p.String())
+			if p.Protocol == netp.ProtocolStringICMP {
+				icmp++
+			}
+		}
+		require.InDelta(t, 1000, icmp, 100)
+	}
+
+	packets := ds.SampleN(r, conns, 512, true)
+	res := netset.EmptyEndpointsTrafficSet()
+	for _, p := range packets {
+		res = res.Union(packetSet(t, p))
+	}
+	require.True(t, res.Equal(conns))
+	require.Panics(t, func() { netset.EmptyEndpointsTrafficSet().Sample(r) })
+
+	sampler := netset.NewTrafficSampler(conns)
+	require.Zero(t, sampler.BigSize().Cmp(conns.BigSize()))
+	res = netset.EmptyEndpointsTrafficSet()
+	for _, p := range ds.SampleN(r, sampler, 512, true) {
+		res = res.Union(packetSet(t, p))
+	}
+	require.True(t, res.Equal(conns))
+	require.Panics(t, func() { netset.NewTrafficSampler(netset.EmptyEndpointsTrafficSet()).Sample(r) })
+}
+
+func TestSampleTransportSet(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	strict := netset.AllICMPTransportStrict()
+	for range 100 {
+		transport := strict.Sample(r)
+		require.Equal(t, netp.ProtocolStringICMP, transport.Protocol)
+		require.True(t, netset.NewICMPTransport(int64(transport.ICMPType), int64(transport.ICMPType),
+			int64(transport.ICMPCode), int64(transport.ICMPCode)).Strict().IsSubset(strict), transport.String())
+	}
+
+	udp := netset.NewUDPTransport(netp.MinPort, netp.MaxPort, 53, 53).Sample(r)
+	require.Equal(t, netp.ProtocolStringUDP, udp.Protocol)
+	require.Equal(t, 53, udp.DstPort)
+
+	require.Equal(t, "TCP 1234->443", netset.Transport{Protocol: netp.ProtocolStringTCP, SrcPort: 1234, DstPort: 443}.String())
+	require.Equal(t, "10.0.0.1 -> 10.0.0.2 ICMP echo-request/0",
+		netset.Packet{Src: "10.0.0.1", Dst: "10.0.0.2", Transport: netset.Transport{Protocol: netp.ProtocolStringICMP, ICMPType: 8}}.String())
+
+	ips := ds.SampleN(r, netset.GetCidrAll(), 1000, true)
+	require.Len(t, ips, 1000)
+}
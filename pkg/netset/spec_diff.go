@@ -0,0 +1,286 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/np-guard/models/pkg/spec"
+)
+
+// SpecChange is the kind of a change between two versions of a spec
+type SpecChange string
+
+const (
+	SpecChangeAdded     SpecChange = "added"
+	SpecChangeRemoved   SpecChange = "removed"
+	SpecChangeModified  SpecChange = "modified"
+	SpecChangeRenamed   SpecChange = "renamed"
+	SpecChangeRepointed SpecChange = "re-pointed"
+)
+
+// SpecDiff is the semantic difference between two versions of a spec (see DiffSpecs).
+// It is marshalled to JSON as its resources, segments and connections.
+type SpecDiff struct {
+	Resources   []ResourceChange   `json:"resources,omitempty"`
+	Segments    []SegmentChange    `json:"segments,omitempty"`
+	Connections []ConnectionChange `json:"connections,omitempty"`
+	// Added is the traffic required by the new version and not by the old version
+	Added *EndpointsTrafficSet `json:"-"`
+	// Removed is the traffic required by the old version and not by the new version
+	Removed *EndpointsTrafficSet `json:"-"`
+}
+
+// ResourceChange is an added, removed, renamed or re-pointed external, subnet, nif or instance.
+// The value of an external, subnet or nif is its address, and the value of an instance is its comma-separated nifs.
+type ResourceChange struct {
+	Change   SpecChange        `json:"change"`
+	Type     spec.ResourceType `json:"type"`
+	Name     string            `json:"name"`
+	OldName  string            `json:"old_name,omitempty"`
+	OldValue string            `json:"old_value,omitempty"`
+	NewValue string            `json:"new_value,omitempty"`
+}
+
+// SegmentChange is an added, removed or modified segment. For a modified segment, OldType is set if its type was
+// changed, and AddedItems and RemovedItems are the changes of its membership.
+type SegmentChange struct {
+	Change       SpecChange       `json:"change"`
+	Name         string           `json:"name"`
+	Type         spec.SegmentType `json:"type"`
+	OldType      spec.SegmentType `json:"old_type,omitempty"`
+	AddedItems   []string         `json:"added_items,omitempty"`
+	RemovedItems []string         `json:"removed_items,omitempty"`
+}
+
+// ConnectionChange is the traffic added by a required connection of the new version, or the traffic removed from a
+// required connection of the old version. Index is the index of the connection in its version.
+type ConnectionChange struct {
+	Change  SpecChange           `json:"change"`
+	Index   int                  `json:"index"`
+	Src     spec.Resource        `json:"src"`
+	Dst     spec.Resource        `json:"dst"`
+	Traffic *EndpointsTrafficSet `json:"-"`
+	// Rows is the report of Traffic (see EndpointsTrafficSet.Report)
+	Rows []ReportRow `json:"traffic"`
+}
+
+// DiffSpecs returns the semantic difference between two versions of a spec, resolved by ResolveSpec:
+//   - the traffic added by each required connection of the new version, i.e., that is not required by the old version
+//   - the traffic removed from each required connection of the old version, i.e., that is not required by the new version
+//   - added, removed, renamed and re-pointed externals, subnets, nifs and instances; a resource is renamed if
+//     a resource of the same type and value is defined under another name only in the other version
+//   - added, removed and modified segments
+func DiffSpecs(oldSpec, newSpec *spec.Spec) (*SpecDiff, error) {
+	oldTraffic, err := ResolveSpec(oldSpec)
+	if err != nil {
+		return nil, fmt.Errorf("old spec: %w", err)
+	}
+	newTraffic, err := ResolveSpec(newSpec)
+	if err != nil {
+		return nil, fmt.Errorf("new spec: %w", err)
+	}
+	res := &SpecDiff{
+		Added:   newTraffic.Total.Subtract(oldTraffic.Total),
+		Removed: oldTraffic.Total.Subtract(newTraffic.Total),
+	}
+	for _, kind := range []spec.ResourceType{spec.ResourceTypeExternal, spec.ResourceTypeSubnet, spec.ResourceTypeNif,
+		spec.ResourceTypeInstance} {
+		res.Resources = append(res.Resources, diffResources(kind, specResources(oldSpec, kind), specResources(newSpec, kind))...)
+	}
+	res.Segments = diffSegments(oldSpec.Segments, newSpec.Segments)
+	res.Connections = append(res.Connections, diffConnections(SpecChangeRemoved, oldSpec, oldTraffic, newTraffic)...)
+	res.Connections = append(res.Connections, diffConnections(SpecChangeAdded, newSpec, newTraffic, oldTraffic)...)
+	return res, nil
+}
+
+// IsEmpty returns true if the versions are semantically equal
+func (d *SpecDiff) IsEmpty() bool {
+	return len(d.Resources) == 0 && len(d.Segments) == 0 && len(d.Connections) == 0
+}
+
+// specResources returns the values of the resources of the given type, by name (see ResourceChange)
+func specResources(s *spec.Spec, kind spec.ResourceType) map[string]string {
+	switch kind {
+	case spec.ResourceTypeExternal:
+		return s.Externals
+	case spec.ResourceTypeSubnet:
+		return s.Subnets
+	case spec.ResourceTypeNif:
+		return s.Nifs
+	case spec.ResourceTypeInstance:
+		res := make(map[string]string, len(s.Instances))
+		for name, nifs := range s.Instances {
+			res[name] = strings.Join(spec.SortedSet(nifs), commaSeparator)
+		}
+		return res
+	}
+	return nil
+}
+
+// sameResourceValue returns true if two values of resources of the given type are equal, e.g., "1.2.3.4" and "1.2.3.4/32"
+func sameResourceValue(kind spec.ResourceType, v1, v2 string) bool {
+	if kind == spec.ResourceTypeInstance || v1 == v2 {
+		return v1 == v2
+	}
+	b1, err1 := IPBlockFromCidrOrAddress(v1)
+	b2, err2 := IPBlockFromCidrOrAddress(v2)
+	return err1 == nil && err2 == nil && b1.Equal(b2)
+}
+
+func diffResources(kind spec.ResourceType, oldValues, newValues map[string]string) []ResourceChange {
+	var res, added, removed []ResourceChange
+	for _, name := range spec.SortedNames(oldValues) {
+		newValue, ok := newValues[name]
+		switch {
+		case !ok:
+			removed = append(removed, ResourceChange{Change: SpecChangeRemoved, Type: kind, Name: name, OldValue: oldValues[name]})
+		case !sameResourceValue(kind, oldValues[name], newValue):
+			res = append(res, ResourceChange{Change: SpecChangeRepointed, Type: kind, Name: name, OldValue: oldValues[name],
+				NewValue: newValue})
+		}
+	}
+	for _, name := range spec.SortedNames(newValues) {
+		if _, ok := oldValues[name]; !ok {
+			added = append(added, ResourceChange{Change: SpecChangeAdded, Type: kind, Name: name, NewValue: newValues[name]})
+		}
+	}
+	// an added resource with the value of a removed resource is a renamed resource
+	for i := range added {
+		j := slices.IndexFunc(removed, func(r ResourceChange) bool { return sameResourceValue(kind, r.OldValue, added[i].NewValue) })
+		if j < 0 {
+			continue
+		}
+		added[i].Change, added[i].OldName, added[i].OldValue = SpecChangeRenamed, removed[j].Name, removed[j].OldValue
+		removed = slices.Delete(removed, j, j+1)
+	}
+	res = append(res, added...)
+	return append(res, removed...)
+}
+
+func diffSegments(oldSegments, newSegments spec.SpecSegments) []SegmentChange {
+	var res []SegmentChange
+	for _, name := range spec.SortedNames(oldSegments) {
+		if _, ok := newSegments[name]; !ok {
+			res = append(res, SegmentChange{Change: SpecChangeRemoved, Name: name, Type: oldSegments[name].Type,
+				RemovedItems: spec.SortedSet(oldSegments[name].Items)})
+		}
+	}
+	for _, name := range spec.SortedNames(newSegments) {
+		newSegment := newSegments[name]
+		oldSegment, ok := oldSegments[name]
+		if !ok {
+			res = append(res, SegmentChange{Change: SpecChangeAdded, Name: name, Type: newSegment.Type,
+				AddedItems: spec.SortedSet(newSegment.Items)})
+			continue
+		}
+		change := SegmentChange{Change: SpecChangeModified, Name: name, Type: newSegment.Type,
+			AddedItems: missingStrings(newSegment.Items, oldSegment.Items), RemovedItems: missingStrings(oldSegment.Items, newSegment.Items)}
+		if oldSegment.Type != newSegment.Type {
+			change.OldType = oldSegment.Type
+		}
+		if change.OldType != "" || len(change.AddedItems) > 0 || len(change.RemovedItems) > 0 {
+			res = append(res, change)
+		}
+	}
+	return res
+}
+
+// missingStrings returns the sorted strings of s1 that are not in s2
+func missingStrings(s1, s2 []string) []string {
+	var res []string
+	for _, s := range spec.SortedSet(s1) {
+		if !slices.Contains(s2, s) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// diffConnections returns the traffic of each required connection of s that is not in the total traffic of other
+func diffConnections(change SpecChange, s *spec.Spec, traffic, other *SpecTraffic) []ConnectionChange {
+	var res []ConnectionChange
+	for i, conn := range s.RequiredConnections {
+		diff := traffic.Connections[i].Subtract(other.Total)
+		if diff.IsEmpty() {
+			continue
+		}
+		res = append(res, ConnectionChange{Change: change, Index: i, Src: conn.Src, Dst: conn.Dst, Traffic: diff,
+			Rows: diff.Report(ReportOptions{})})
+	}
+	return res
+}
+
+func (r *ResourceChange) String() string {
+	label := fmt.Sprintf("%s %q", r.Type, r.Name)
+	switch r.Change {
+	case SpecChangeAdded:
+		return fmt.Sprintf("%s added: %s", label, r.NewValue)
+	case SpecChangeRemoved:
+		return fmt.Sprintf("%s removed: %s", label, r.OldValue)
+	case SpecChangeRenamed:
+		return fmt.Sprintf("%s renamed from %q", label, r.OldName)
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", label, r.Change, r.OldValue, r.NewValue)
+}
+
+func (s *SegmentChange) String() string {
+	res := []string{fmt.Sprintf("segment %q %s", s.Name, s.Change)}
+	if s.OldType != "" {
+		res = append(res, fmt.Sprintf("type %s -> %s", s.OldType, s.Type))
+	}
+	if len(s.AddedItems) > 0 {
+		res = append(res, "added "+strings.Join(s.AddedItems, commaSeparator))
+	}
+	if len(s.RemovedItems) > 0 {
+		res = append(res, "removed "+strings.Join(s.RemovedItems, commaSeparator))
+	}
+	return strings.Join(res, "; ")
+}
+
+func (c *ConnectionChange) String() string {
+	sign := "+"
+	if c.Change == SpecChangeRemoved {
+		sign = "-"
+	}
+	lines := []string{fmt.Sprintf("%s required-connections[%d] %s %q -> %s %q:", sign, c.Index, c.Src.Type, c.Src.Name,
+		c.Dst.Type, c.Dst.Name)}
+	for i := range c.Rows {
+		lines = append(lines, fmt.Sprintf("  %s %s", sign, c.Rows[i].summary()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// summary returns a single-line representation of the row, e.g., "10.0.0.0/24 -> 8.8.8.8 UDP src-ports any dst-ports 53"
+func (r *ReportRow) summary() string {
+	res := fmt.Sprintf("%s -> %s %s", r.Src, r.Dst, r.Protocol)
+	if r.SrcPorts != "" || r.DstPorts != "" {
+		res += fmt.Sprintf(" src-ports %s dst-ports %s", r.SrcPorts, r.DstPorts)
+	}
+	if r.ICMPType != "" || r.ICMPCode != "" {
+		res += fmt.Sprintf(" type %s code %s", r.ICMPType, r.ICMPCode)
+	}
+	return res
+}
+
+// String returns a human-readable representation of the difference, a line for each change of a resource or a
+// segment, followed by the traffic added and removed by each required connection
+func (d *SpecDiff) String() string {
+	var lines []string
+	for i := range d.Resources {
+		lines = append(lines, d.Resources[i].String())
+	}
+	for i := range d.Segments {
+		lines = append(lines, d.Segments[i].String())
+	}
+	for i := range d.Connections {
+		lines = append(lines, d.Connections[i].String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/netset"
+	"github.com/np-guard/models/pkg/spec"
+)
+
+const oldDiffSpec = `
+externals:
+  dns: 8.8.8.8
+  proxy: 1.2.3.4
+subnets:
+  sub1: 10.240.10.0/24
+  sub2: 10.240.20.0/24
+segments:
+  web: {type: subnet, items: [sub1]}
+required-connections:
+  - src: {name: web, type: segment}
+    dst: {name: dns, type: external}
+    allowed-protocols: [{protocol: UDP, min_destination_port: 53, max_destination_port: 53}]
+  - src: {name: sub2, type: subnet}
+    dst: {name: proxy, type: external}
+    allowed-protocols: [{protocol: TCP, min_destination_port: 443, max_destination_port: 443}]
+`
+
+const newDiffSpec = `
+externals:
+  dns: 8.8.8.8/32
+  web-proxy: 1.2.3.4
+subnets:
+  sub1: 10.240.10.0/24
+  sub2: 10.240.30.0/24
+segments:
+  web: {type: subnet, items: [sub1, sub2]}
+required-connections:
+  - src: {name: sub2, type: subnet}
+    dst: {name: web-proxy, type: external}
+    allowed-protocols: [{protocol: TCP, min_destination_port: 443, max_destination_port: 443}]
+  - src: {name: web, type: segment}
+    dst: {name: dns, type: external}
+    allowed-protocols: [{protocol: UDP, min_destination_port: 53, max_destination_port: 53}, {protocol: ICMP, type: 8}]
+`
+
+func parseDiffSpecs(t *testing.T) (oldSpec, newSpec *spec.Spec) {
+	t.Helper()
+	oldSpec, err := spec.ParseYAML([]byte(oldDiffSpec))
+	require.NoError(t, err)
+	newSpec, err = spec.ParseYAML([]byte(newDiffSpec))
+	require.NoError(t, err)
+	return oldSpec, newSpec
+}
+
+func TestDiffSpecs(t *testing.T) {
+	oldSpec, newSpec := parseDiffSpecs(t)
+	diff, err := netset.DiffSpecs(oldSpec, newSpec)
+	require.NoError(t, err)
+	require.False(t, diff.IsEmpty())
+
+	require.Equal(t, []netset.ResourceChange{
+		{Change: netset.SpecChangeRenamed, Type: spec.ResourceTypeExternal, Name: "web-proxy", OldName: "proxy",
+			OldValue: "1.2.3.4", NewValue: "1.2.3.4"},
+		{Change: netset.SpecChangeRepointed, Type: spec.ResourceTypeSubnet, Name: "sub2", OldValue: "10.240.20.0/24",
+			NewValue: "10.240.30.0/24"},
+	}, diff.Resources)
+	require.Equal(t, []netset.SegmentChange{
+		{Change: netset.SpecChangeModified, Name: "web", Type: spec.SegmentTypeSubnet, AddedItems: []string{"sub2"}},
+	}, diff.Segments)
+
+	require.Equal(t, `external "web-proxy" renamed from "proxy"
+subnet "sub2" re-pointed: 10.240.20.0/24 -> 10.240.30.0/24
+segment "web" modified; added sub2
+- required-connections[1] subnet "sub2" -> external "proxy":
+  - 10.240.20.0/24 -> 1.2.3.4 TCP src-ports any dst-ports 443
++ required-connections[0] subnet "sub2" -> external "web-proxy":
+  + 10.240.30.0/24 -> 1.2.3.4 TCP src-ports any dst-ports 443
++ required-connections[1] segment "web" -> external "dns":
+  + 10.240.10.0/24 -> 8.8.8.8 ICMP type echo-request code any
+  + 10.240.30.0/24 -> 8.8.8.8 UDP src-ports any dst-ports 53
+  + 10.240.30.0/24 -> 8.8.8.8 ICMP type echo-request code any`, diff.String())
+
+	data, err := json.Marshal(diff.Connections[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"change": "removed", "index": 1,
+		"src": {"name": "sub2", "type": "subnet"}, "dst": {"name": "proxy", "type": "external"},
+		"traffic": [{"src": "10.240.20.0/24", "dst": "1.2.3.4", "protocol": "TCP", "src_ports": "any", "dst_ports": "443"}]}`,
+		string(data))
+
+	require.True(t, diff.Removed.Equal(diff.Connections[0].Traffic))
+	require.True(t, diff.Added.Equal(diff.Connections[1].Traffic.Union(diff.Connections[2].Traffic)))
+
+	diff, err = netset.DiffSpecs(newSpec, newSpec)
+	require.NoError(t, err)
+	require.True(t, diff.IsEmpty())
+	require.Empty(t, diff.String())
+}
+
+func TestDiffSpecsResources(t *testing.T) {
+	oldSpec, newSpec := parseDiffSpecs(t)
+	oldSpec.Instances = spec.SpecInstances{"vsi1": {"nif1", "nif2"}, "vsi2": {"nif3"}}
+	oldSpec.Segments["db"] = spec.Segment{Type: spec.SegmentTypeCidr, Items: []string{"10.0.0.0/8"}}
+	newSpec.Instances = spec.SpecInstances{"vsi1": {"nif2", "nif1"}, "vsi3": {"nif4"}}
+	newSpec.Segments["web"] = spec.Segment{Type: spec.SegmentTypeCidr, Items: []string{"10.240.10.0/24"}}
+	diff, err := netset.DiffSpecs(oldSpec, newSpec)
+	require.NoError(t, err)
+	require.Equal(t, []netset.ResourceChange{
+		{Change: netset.SpecChangeRenamed, Type: spec.ResourceTypeExternal, Name: "web-proxy", OldName: "proxy",
+			OldValue: "1.2.3.4", NewValue: "1.2.3.4"},
+		{Change: netset.SpecChangeRepointed, Type: spec.ResourceTypeSubnet, Name: "sub2", OldValue: "10.240.20.0/24",
+			NewValue: "10.240.30.0/24"},
+		{Change: netset.SpecChangeAdded, Type: spec.ResourceTypeInstance, Name: "vsi3", NewValue: "nif4"},
+		{Change: netset.SpecChangeRemoved, Type: spec.ResourceTypeInstance, Name: "vsi2", OldValue: "nif3"},
+	}, diff.Resources)
+	require.Equal(t, []netset.SegmentChange{
+		{Change: netset.SpecChangeRemoved, Name: "db", Type: spec.SegmentTypeCidr, RemovedItems: []string{"10.0.0.0/8"}},
+		{Change: netset.SpecChangeModified, Name: "web", Type: spec.SegmentTypeCidr, OldType: spec.SegmentTypeSubnet,
+			AddedItems: []string{"10.240.10.0/24"}, RemovedItems: []string{"sub1"}},
+	}, diff.Segments)
+	require.Equal(t, `segment "web" modified; type subnet -> cidr; added 10.240.10.0/24; removed sub1`, diff.Segments[1].String())
+
+	oldSpec.RequiredConnections[0].Src = spec.Resource{Name: "vpe1", Type: spec.ResourceTypeVpe}
+	_, err = netset.DiffSpecs(oldSpec, newSpec)
+	require.EqualError(t, err, `old spec: required-connections[0].src: cannot resolve vpe "vpe1" to IP addresses`)
+}
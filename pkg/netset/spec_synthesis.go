@@ -0,0 +1,122 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"math/big"
+
+	"github.com/np-guard/models/pkg/spec"
+)
+
+// namedBlock is a resource defined by a spec, with its IP addresses
+type namedBlock struct {
+	resource spec.Resource
+	block    *IPBlock
+}
+
+// SynthesizeSpec returns a spec whose required connections resolve (see ResolveSpec) exactly to the given traffic.
+// The returned spec defines the externals, subnets, nifs, instances and segments of names, which is used to name the
+// src and dst of the required connections; addresses not covered by these resources are named by their CIDRs.
+// Connections between the same resources are merged, and their allowed protocols are generated by ToJSON, from
+// non-strict transports (see TransportSet.NonStrict), as a spec resolves its protocols to non-strict transports.
+// A pair of connections in opposite directions with the same allowed transports is merged into a bidirectional one.
+// Specs do not record strictness: the spec of a strict traffic resolves to connections that are Equal to the traffic,
+// but non-strict, so that their Complement also holds the ICMP connections that are not valid by RFC.
+func SynthesizeSpec(traffic *EndpointsTrafficSet, names *spec.Spec) (*spec.Spec, error) {
+	blocks, err := namedBlocks(names)
+	if err != nil {
+		return nil, err
+	}
+	type endpoints struct{ src, dst spec.Resource }
+	var pairs []endpoints
+	transports := map[endpoints]*TransportSet{}
+	for _, cube := range traffic.Partitions() {
+		dsts := coverBlock(cube.S2, blocks)
+		for _, src := range coverBlock(cube.S1, blocks) {
+			for _, dst := range dsts {
+				pair := endpoints{src, dst}
+				if t, ok := transports[pair]; ok {
+					transports[pair] = t.Union(cube.S3)
+					continue
+				}
+				pairs = append(pairs, pair)
+				transports[pair] = cube.S3
+			}
+		}
+	}
+
+	res := &spec.Spec{Externals: names.Externals, Instances: names.Instances, Nifs: names.Nifs, Segments: names.Segments,
+		Subnets: names.Subnets, RequiredConnections: []spec.SpecRequiredConnectionsElem{}}
+	merged := map[endpoints]bool{}
+	for _, pair := range pairs {
+		if merged[pair] {
+			continue
+		}
+		reverse := endpoints{pair.dst, pair.src}
+		reverseTransports, ok := transports[reverse]
+		bidirectional := ok && pair != reverse && reverseTransports.Equal(transports[pair])
+		merged[reverse] = bidirectional
+		res.RequiredConnections = append(res.RequiredConnections, spec.SpecRequiredConnectionsElem{Src: pair.src, Dst: pair.dst,
+			Bidirectional: bidirectional, AllowedProtocols: spec.ProtocolList(ToJSON(transports[pair].NonStrict()))})
+	}
+	return res, nil
+}
+
+// namedBlocks returns the resources defined by the spec, by the order of their types and names.
+// Segments of VPEs are skipped, as they cannot be resolved to IP addresses.
+func namedBlocks(s *spec.Spec) ([]namedBlock, error) {
+	var res []namedBlock
+	for _, resources := range []struct {
+		kind  spec.ResourceType
+		names []string
+	}{
+		{spec.ResourceTypeSegment, spec.SortedNames(s.Segments)},
+		{spec.ResourceTypeExternal, spec.SortedNames(s.Externals)},
+		{spec.ResourceTypeSubnet, spec.SortedNames(s.Subnets)},
+		{spec.ResourceTypeInstance, spec.SortedNames(s.Instances)},
+		{spec.ResourceTypeNif, spec.SortedNames(s.Nifs)},
+	} {
+		for _, name := range resources.names {
+			if resources.kind == spec.ResourceTypeSegment && s.Segments[name].Type == spec.SegmentTypeVpe {
+				continue
+			}
+			block, err := ResolveSpecResource(s, resources.kind, name)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, namedBlock{spec.Resource{Name: name, Type: resources.kind}, block})
+		}
+	}
+	return res, nil
+}
+
+// coverBlock returns resources whose union is exactly the given block. It greedily chooses the named resources
+// contained in the block that cover the most uncovered addresses, and names the remaining addresses by their CIDRs.
+func coverBlock(block *IPBlock, blocks []namedBlock) []spec.Resource {
+	var res []spec.Resource
+	uncovered := block
+	for !uncovered.IsEmpty() {
+		best, bestSize := -1, new(big.Int)
+		for i := range blocks {
+			if !blocks[i].block.IsSubset(block) {
+				continue
+			}
+			if size := blocks[i].block.Intersect(uncovered).BigSize(); size.Cmp(bestSize) > 0 {
+				best, bestSize = i, size
+			}
+		}
+		if best < 0 {
+			break
+		}
+		res = append(res, blocks[best].resource)
+		uncovered = uncovered.Subtract(blocks[best].block)
+	}
+	for _, cidr := range uncovered.ToCidrList() {
+		res = append(res, spec.Resource{Name: cidr, Type: spec.ResourceTypeCidr})
+	}
+	return res
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+	"github.com/np-guard/models/pkg/spec"
+)
+
+func TestSynthesizeSpec(t *testing.T) {
+	names := &spec.Spec{
+		Externals: spec.SpecExternals{"dns": "8.8.8.8"},
+		Subnets:   spec.SpecSubnets{"sub1": "10.240.10.0/24", "sub2": "10.240.20.0/24", "sub3": "10.240.30.0/24"},
+		Segments: spec.SpecSegments{
+			"app": {Type: spec.SegmentTypeSubnet, Items: []string{"sub1", "sub2"}},
+			"vpe": {Type: spec.SegmentTypeVpe, Items: []string{"vpe1"}},
+		},
+	}
+	app, _ := netset.IPBlockFromCidrList([]string{"10.240.10.0/24", "10.240.20.0/24"})
+	sub3, _ := netset.IPBlockFromCidr("10.240.30.0/24")
+	dns, _ := netset.IPBlockFromIPAddress("8.8.8.8")
+	// sub3 and two addresses that are not named
+	other, _ := netset.IPBlockFromIPRangeStr("10.240.30.0-10.240.31.1")
+	tcp443 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 443, 443)
+	udp53 := netset.NewUDPTransport(netp.MinPort, netp.MaxPort, 53, 53)
+
+	traffic := netset.NewEndpointsTrafficSet(app.Union(sub3), dns, udp53).
+		Union(netset.NewEndpointsTrafficSet(app, other, tcp443)).
+		Union(netset.NewEndpointsTrafficSet(other, app, tcp443)).
+		Union(netset.NewEndpointsTrafficSet(sub3, sub3, netset.AllTransports()))
+
+	s, err := netset.SynthesizeSpec(traffic, names)
+	require.NoError(t, err)
+	require.NoError(t, s.Validate())
+
+	resolved, err := netset.ResolveSpec(s)
+	require.NoError(t, err)
+	require.True(t, resolved.Total.Equal(traffic), resolved.Total.String())
+
+	type entry struct {
+		src, dst      string
+		bidirectional bool
+	}
+	var entries []entry
+	for _, conn := range s.RequiredConnections {
+		entries = append(entries, entry{conn.Src.Name, conn.Dst.Name, conn.Bidirectional})
+	}
+	require.ElementsMatch(t, []entry{
+		{"app", "dns", false},
+		{"sub3", "dns", false},
+		{"app", "sub3", true},
+		{"app", "10.240.31.0/31", true},
+		{"sub3", "sub3", false},
+	}, entries)
+
+	formatted, err := s.FormatJSON()
+	require.NoError(t, err)
+	parsed, err := spec.ParseJSON(formatted)
+	require.NoError(t, err)
+	resolved, err = netset.ResolveSpec(parsed)
+	require.NoError(t, err)
+	require.True(t, resolved.Total.Equal(traffic))
+
+	// strictness is not recorded by the spec
+	strict := netset.NewEndpointsTrafficSet(app, dns, netset.AllICMPTransportStrict())
+	s, err = netset.SynthesizeSpec(strict, names)
+	require.NoError(t, err)
+	resolved, err = netset.ResolveSpec(s)
+	require.NoError(t, err)
+	require.True(t, resolved.Total.Equal(strict))
+	require.False(t, resolved.Total.Complement().Equal(strict.Complement()))
+	nonStrict := netset.NewEndpointsTrafficSet(app, dns, netset.AllICMPTransportStrict().NonStrict())
+	require.True(t, resolved.Total.Complement().Equal(nonStrict.Complement()))
+
+	s, err = netset.SynthesizeSpec(netset.EmptyEndpointsTrafficSet(), names)
+	require.NoError(t, err)
+	require.Empty(t, s.RequiredConnections)
+	require.NoError(t, s.Validate())
+}
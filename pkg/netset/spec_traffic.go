@@ -0,0 +1,95 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"fmt"
+
+	"github.com/np-guard/models/pkg/spec"
+)
+
+// SpecTraffic is the traffic required by a spec
+type SpecTraffic struct {
+	// Connections holds the traffic required by each of the required connections of the spec, in their order
+	Connections []*EndpointsTrafficSet
+	// Total is the union of Connections
+	Total *EndpointsTrafficSet
+}
+
+// ResolveSpec returns the traffic required by the spec, resolving the src and dst of each required connection to
+// its IP addresses, and its allowed protocols to a set of transports. A connection with no allowed protocols allows
+// all transports, and a bidirectional connection also allows the traffic from its dst to its src.
+// It returns an error if a src or dst cannot be resolved, e.g., if it is not defined by the spec or it is a VPE.
+func ResolveSpec(s *spec.Spec) (*SpecTraffic, error) {
+	res := &SpecTraffic{Connections: make([]*EndpointsTrafficSet, len(s.RequiredConnections)), Total: EmptyEndpointsTrafficSet()}
+	for i := range s.RequiredConnections {
+		conn := &s.RequiredConnections[i]
+		src, err := ResolveSpecResource(s, conn.Src.Type, conn.Src.Name)
+		if err != nil {
+			return nil, fmt.Errorf("required-connections[%d].src: %w", i, err)
+		}
+		dst, err := ResolveSpecResource(s, conn.Dst.Type, conn.Dst.Name)
+		if err != nil {
+			return nil, fmt.Errorf("required-connections[%d].dst: %w", i, err)
+		}
+		transports := AllTransports()
+		if len(conn.AllowedProtocols) > 0 {
+			if transports, err = FromJSON(conn.AllowedProtocols); err != nil {
+				return nil, fmt.Errorf("required-connections[%d].allowed-protocols: %w", i, err)
+			}
+		}
+		res.Connections[i] = NewEndpointsTrafficSet(src, dst, transports)
+		if conn.Bidirectional {
+			res.Connections[i] = res.Connections[i].Union(NewEndpointsTrafficSet(dst, src, transports))
+		}
+		res.Total = res.Total.Union(res.Connections[i])
+	}
+	return res, nil
+}
+
+// ResolveSpecResource returns the IP addresses of a resource of the given type, as defined by the spec.
+// Resources of type cidr are resolved by their name, and segments by the union of their items.
+func ResolveSpecResource(s *spec.Spec, kind spec.ResourceType, name string) (*IPBlock, error) {
+	var value string
+	var ok bool
+	switch kind {
+	case spec.ResourceTypeCidr:
+		return IPBlockFromCidrOrAddress(name)
+	case spec.ResourceTypeExternal:
+		value, ok = s.Externals[name]
+	case spec.ResourceTypeSubnet:
+		value, ok = s.Subnets[name]
+	case spec.ResourceTypeNif:
+		value, ok = s.Nifs[name]
+	case spec.ResourceTypeInstance:
+		if nifs, found := s.Instances[name]; found {
+			return resolveSpecItems(s, spec.ResourceTypeNif, nifs)
+		}
+	case spec.ResourceTypeSegment:
+		if segment, found := s.Segments[name]; found {
+			return resolveSpecItems(s, spec.ResourceType(segment.Type), segment.Items)
+		}
+	default:
+		return nil, fmt.Errorf("cannot resolve %s %q to IP addresses", kind, name)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s %q is not defined", kind, name)
+	}
+	return IPBlockFromCidrOrAddress(value)
+}
+
+func resolveSpecItems(s *spec.Spec, kind spec.ResourceType, items []string) (*IPBlock, error) {
+	res := NewIPBlock()
+	for _, item := range items {
+		block, err := ResolveSpecResource(s, kind, item)
+		if err != nil {
+			return nil, err
+		}
+		res = res.Union(block)
+	}
+	return res, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+	"github.com/np-guard/models/pkg/spec"
+)
+
+func TestFromJSON(t *testing.T) {
+	for _, conns := range []*netset.TransportSet{
+		netset.NoTransports(),
+		netset.AllTransports(),
+		netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 80, 90).Union(netset.NewUDPTransport(53, 53, 1000, 2000)),
+		netset.NewICMPTransport(3, 3, 0, 5).Union(netset.NewICMPTransport(0, 10, 0, 0)).Union(netset.AllUDPTransport()),
+		netset.AllICMPTransport(),
+	} {
+		res, err := netset.FromJSON(spec.ProtocolList(netset.ToJSON(conns)))
+		require.NoError(t, err)
+		require.True(t, res.Equal(conns), conns.String())
+	}
+
+	_, err := netset.FromJSON(spec.ProtocolList{map[string]any{"protocol": "SCTP"}})
+	require.ErrorContains(t, err, `unknown protocol "SCTP"`)
+
+	for _, c := range []struct {
+		protocol spec.Protocol
+		err      string
+	}{
+		{spec.TcpUdp{Protocol: spec.TcpUdpProtocolTCP, MinDestinationPort: 70000, MaxDestinationPort: 70000},
+			"item 1: min_destination_port 70000 is not in the range [1-65535]"},
+		{spec.TcpUdp{Protocol: spec.TcpUdpProtocolUDP, MinSourcePort: -5},
+			"item 1: min_source_port -5 is not in the range [1-65535]"},
+		{spec.TcpUdp{Protocol: spec.TcpUdpProtocolTCP, MinDestinationPort: 90, MaxDestinationPort: 80},
+			"item 1: min_destination_port 90 is greater than max_destination_port 80"},
+		{spec.Icmp{Protocol: spec.IcmpProtocolICMP, Type: intPtr(300)}, "item 1: type 300 is not in the range [0-254]"},
+		{spec.Icmp{Protocol: spec.IcmpProtocolICMP, Code: intPtr(-1)}, "item 1: code -1 is not in the range [0-255]"},
+	} {
+		_, err := netset.FromJSON(spec.ProtocolList{spec.AnyProtocol{Protocol: spec.AnyProtocolProtocolANY}, c.protocol})
+		require.EqualError(t, err, c.err)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestResolveSpec(t *testing.T) {
+	s, err := spec.ParseYAML([]byte(`
+externals:
+  dns: 8.8.8.8
+subnets:
+  sub1: 10.240.10.0/24
+  sub2: 10.240.20.0/24
+nifs:
+  nif1: 10.240.10.4
+  nif2: 10.240.10.5
+instances:
+  vsi1: [nif1, nif2]
+segments:
+  subnets: {type: subnet, items: [sub1, sub2]}
+required-connections:
+  - src: {name: subnets, type: segment}
+    dst: {name: dns, type: external}
+    allowed-protocols: [{protocol: UDP, min_destination_port: 53, max_destination_port: 53}]
+  - src: {name: vsi1, type: instance}
+    dst: {name: 10.240.20.0/28, type: cidr}
+    bidirectional: true
+`))
+	require.NoError(t, err)
+	traffic, err := netset.ResolveSpec(s)
+	require.NoError(t, err)
+
+	subnets, _ := netset.IPBlockFromCidrList([]string{"10.240.10.0/24", "10.240.20.0/24"})
+	dns, _ := netset.IPBlockFromIPAddress("8.8.8.8")
+	vsi1, _ := netset.IPBlockFromIPRangeStr("10.240.10.4-10.240.10.5")
+	cidr, _ := netset.IPBlockFromCidr("10.240.20.0/28")
+	dns53 := netset.NewEndpointsTrafficSet(subnets, dns, netset.NewUDPTransport(netp.MinPort, netp.MaxPort, 53, 53))
+	vsiCidr := netset.NewEndpointsTrafficSet(vsi1, cidr, netset.AllTransports()).
+		Union(netset.NewEndpointsTrafficSet(cidr, vsi1, netset.AllTransports()))
+	require.Len(t, traffic.Connections, 2)
+	require.True(t, traffic.Connections[0].Equal(dns53))
+	require.True(t, traffic.Connections[1].Equal(vsiCidr))
+	require.True(t, traffic.Total.Equal(dns53.Union(vsiCidr)))
+
+	s.RequiredConnections[1].Dst = spec.Resource{Name: "vpe1", Type: spec.ResourceTypeVpe}
+	_, err = netset.ResolveSpec(s)
+	require.EqualError(t, err, `required-connections[1].dst: cannot resolve vpe "vpe1" to IP addresses`)
+	s.RequiredConnections[1].Dst = spec.Resource{Name: "nif3", Type: spec.ResourceTypeNif}
+	_, err = netset.ResolveSpec(s)
+	require.EqualError(t, err, `required-connections[1].dst: nif "nif3" is not defined`)
+
+	s.RequiredConnections[1].Dst = spec.Resource{Name: "nif2", Type: spec.ResourceTypeNif}
+	s.RequiredConnections[0].AllowedProtocols = spec.ProtocolList{spec.TcpUdp{Protocol: spec.TcpUdpProtocolUDP, MaxSourcePort: 70000}}
+	_, err = netset.ResolveSpec(s)
+	require.EqualError(t, err,
+		`required-connections[0].allowed-protocols: item 0: max_source_port 70000 is not in the range [1-65535]`)
+}
@@ -8,6 +8,7 @@ package netset
 
 import (
 	"log"
+	"math/big"
 	"slices"
 	"sort"
 	"strings"
@@ -77,12 +78,22 @@ func (c *TCPUDPSet) Size() int {
 	return c.props.Size()
 }
 
+// BigSize returns the exact number of (protocol, src port, dst port) triples in the set
+func (c *TCPUDPSet) BigSize() *big.Int {
+	return c.props.BigSize()
+}
+
 // SwapPorts returns a new TCPUDPSet object, built from the input TCPUDPSet object,
 // with src ports and dst ports swapped
 func (c *TCPUDPSet) SwapPorts() *TCPUDPSet {
 	return &TCPUDPSet{props: ds.MapTripleSet(c.props, ds.Triple[*ProtocolSet, *PortSet, *PortSet].Swap23)}
 }
 
+// Compare returns -1 if c<other, 1 if c>other, 0 o.w.
+func (c *TCPUDPSet) Compare(other *TCPUDPSet) int {
+	return c.props.Compare(other.props)
+}
+
 // Subtract returns the subtraction of the other from c
 func (c *TCPUDPSet) Subtract(other *TCPUDPSet) *TCPUDPSet {
 	return &TCPUDPSet{props: c.props.Subtract(other.props)}
@@ -141,6 +152,16 @@ func (c *TCPUDPSet) IsAll() bool {
 	return c.Equal(all)
 }
 
+// Universe returns the set of all TCP and UDP connections
+func (c *TCPUDPSet) Universe() *TCPUDPSet {
+	return AllTCPUDPSet()
+}
+
+// Complement returns the TCP and UDP connections not in this set
+func (c *TCPUDPSet) Complement() *TCPUDPSet {
+	return AllTCPUDPSet().Subtract(c)
+}
+
 func protocolStringToCode(protocol netp.ProtocolString) int64 {
 	switch protocol {
 	case netp.ProtocolStringTCP:
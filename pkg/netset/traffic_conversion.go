@@ -0,0 +1,128 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"slices"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/interval"
+)
+
+// ToIPTraffic returns the traffic between the IP blocks of the endpoints in this set, given a mapping from endpoint
+// IDs to their IP blocks. Endpoints with no IP block in the mapping are ignored.
+func (c *DiscreteEndpointsTrafficSet) ToIPTraffic(ips map[int64]*IPBlock) *EndpointsTrafficSet {
+	res := EmptyEndpointsTrafficSet()
+	for _, cube := range c.Partitions() {
+		src, dst := endpointsIPs(cube.S1, ips), endpointsIPs(cube.S2, ips)
+		if src.IsEmpty() || dst.IsEmpty() {
+			continue
+		}
+		res = res.Union(NewEndpointsTrafficSet(src, dst, cube.S3))
+	}
+	return res
+}
+
+func endpointsIPs(endpoints *interval.CanonicalSet, ips map[int64]*IPBlock) *IPBlock {
+	res := NewIPBlock()
+	for id, block := range ips {
+		if endpoints.Contains(id) {
+			res = res.Union(block)
+		}
+	}
+	return res
+}
+
+// PartialEndpoint is an endpoint whose IPs are only partly covered by some traffic: the traffic is allowed to or
+// from some of its IPs, but not from or to all of them
+type PartialEndpoint struct {
+	ID int64
+	// Uncovered holds the IPs of the endpoint that are excluded from traffic allowed to or from its other IPs
+	Uncovered *IPBlock
+}
+
+type ipCube = ds.Triple[*IPBlock, *IPBlock, *TransportSet]
+
+// FromIPTraffic returns the traffic between endpoints, given a mapping from endpoint IDs to their IP blocks: for each
+// pair of endpoints, the transports allowed from all the IPs of the source endpoint to all the IPs of the destination
+// endpoint. It also returns the endpoints whose IPs are only partly covered by the traffic, sorted by ID; such traffic
+// is not included in the first result. Endpoints with an empty IP block are ignored.
+// The traffic is computed per source endpoint, grouping the destination endpoints by the cubes of its traffic.
+func FromIPTraffic(t *EndpointsTrafficSet, ips map[int64]*IPBlock) (*DiscreteEndpointsTrafficSet, []PartialEndpoint) {
+	ids := make([]int64, 0, len(ips))
+	for id, block := range ips {
+		if !block.IsEmpty() {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	res := EmptyDiscreteEndpointsTrafficSet()
+	partial := []PartialEndpoint{}
+	for _, id := range ids {
+		block := ips[id]
+		out, srcUncovered := uniformTraffic(t, NewEndpointsTrafficSet(block, GetCidrAll(), AllTransports()),
+			func(c ipCube) *EndpointsTrafficSet { return NewEndpointsTrafficSet(block, c.S2, c.S3) },
+			func(c ipCube) *IPBlock { return c.S1 })
+		_, dstUncovered := uniformTraffic(t, NewEndpointsTrafficSet(GetCidrAll(), block, AllTransports()),
+			func(c ipCube) *EndpointsTrafficSet { return NewEndpointsTrafficSet(c.S1, block, c.S3) },
+			func(c ipCube) *IPBlock { return c.S2 })
+		if uncovered := srcUncovered.Union(dstUncovered); !uncovered.IsEmpty() {
+			partial = append(partial, PartialEndpoint{ID: id, Uncovered: uncovered})
+		}
+
+		// all the cubes of out have the IPs of the endpoint as src; a destination endpoint is allowed a transport
+		// if its IPs are contained in the dsts of the cubes allowing the transport
+		cubes := out.Partitions()
+		srcSet := interval.New(id, id).ToSet()
+		for _, conn := range atomize(cubeTransports(cubes)) {
+			dstIPs := NewIPBlock()
+			for _, cube := range cubes {
+				if conn.IsSubset(cube.S3) {
+					dstIPs = dstIPs.Union(cube.S2)
+				}
+			}
+			dstSet := interval.NewCanonicalSet()
+			for _, dstID := range ids {
+				if ips[dstID].IsSubset(dstIPs) {
+					dstSet.AddInterval(interval.New(dstID, dstID))
+				}
+			}
+			if !dstSet.IsEmpty() {
+				res = res.Union(NewDiscreteEndpointsTrafficSet(srcSet, dstSet, conn))
+			}
+		}
+	}
+	return res, partial
+}
+
+// uniformTraffic returns the traffic of t within scope (the traffic from or to an endpoint), that is allowed for
+// all the IPs of the endpoint, and the IPs of the endpoint that are excluded from traffic allowed for its other IPs.
+// lift replaces the src or dst of a cube with all the IPs of the endpoint, and endpointIPs returns them.
+func uniformTraffic(t, scope *EndpointsTrafficSet, lift func(ipCube) *EndpointsTrafficSet,
+	endpointIPs func(ipCube) *IPBlock) (uniform *EndpointsTrafficSet, uncovered *IPBlock) {
+	allowed, missing := t.Intersect(scope), scope.Subtract(t)
+	liftedAllowed, liftedMissing := EmptyEndpointsTrafficSet(), EmptyEndpointsTrafficSet()
+	for _, cube := range allowed.Partitions() {
+		liftedAllowed = liftedAllowed.Union(lift(cube))
+	}
+	for _, cube := range missing.Partitions() {
+		liftedMissing = liftedMissing.Union(lift(cube))
+	}
+	uncovered = NewIPBlock()
+	for _, cube := range missing.Intersect(liftedAllowed).Partitions() {
+		uncovered = uncovered.Union(endpointIPs(cube))
+	}
+	return scope.Subtract(liftedMissing), uncovered
+}
+
+func cubeTransports(cubes []ipCube) []*TransportSet {
+	res := make([]*TransportSet, len(cubes))
+	for i, cube := range cubes {
+		res[i] = cube.S3
+	}
+	return res
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/interval"
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func TestIPTrafficConversion(t *testing.T) {
+	vsi0, _ := netset.IPBlockFromCidr("10.240.10.0/30")
+	vsi1, _ := netset.IPBlockFromCidr("10.240.20.0/30")
+	vsi2, _ := netset.IPBlockFromCidr("10.240.30.0/30")
+	ips := map[int64]*netset.IPBlock{0: vsi0, 1: vsi1, 2: vsi2}
+	tcp22 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22)
+
+	discrete := netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 1).ToSet(), interval.New(2, 2).ToSet(), tcp22)
+	ipTraffic := discrete.ToIPTraffic(ips)
+	require.True(t, ipTraffic.Equal(netset.NewEndpointsTrafficSet(vsi0.Union(vsi1), vsi2, tcp22)))
+
+	full, partial := netset.FromIPTraffic(ipTraffic, ips)
+	require.True(t, full.Equal(discrete))
+	require.Empty(t, partial)
+
+	// only a single IP of vsi0 may connect to vsi2 over UDP, and vsi0 may connect to a single IP of vsi1 over ICMP
+	host0, _ := netset.IPBlockFromIPAddress("10.240.10.1")
+	host1, _ := netset.IPBlockFromIPAddress("10.240.20.2")
+	ipTraffic = ipTraffic.Union(netset.NewEndpointsTrafficSet(host0, vsi2, netset.AllUDPTransport())).Union(
+		netset.NewEndpointsTrafficSet(vsi0, host1, netset.AllICMPTransport()))
+	full, partial = netset.FromIPTraffic(ipTraffic, ips)
+	require.True(t, full.Equal(discrete))
+	require.Equal(t, []netset.PartialEndpoint{{ID: 0, Uncovered: vsi0.Subtract(host0)}, {ID: 1, Uncovered: vsi1.Subtract(host1)}},
+		partial)
+
+	// transports allowed to all the IPs of an endpoint by different cubes
+	tcp := netset.AllTCPTransport()
+	ipTraffic = netset.NewEndpointsTrafficSet(vsi0, vsi1.Subtract(host1), tcp.Union(netset.AllUDPTransport())).Union(
+		netset.NewEndpointsTrafficSet(vsi0, host1, tcp))
+	full, partial = netset.FromIPTraffic(ipTraffic, ips)
+	require.True(t, full.Equal(netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 0).ToSet(), interval.New(1, 1).ToSet(), tcp)))
+	require.Equal(t, []netset.PartialEndpoint{{ID: 1, Uncovered: host1}}, partial)
+
+	// the complement is lifted by the IPs of the endpoints only
+	require.True(t, discrete.Complement().ToIPTraffic(ips).Intersect(ipTraffic).Equal(ipTraffic))
+}
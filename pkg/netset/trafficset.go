@@ -8,6 +8,8 @@ package netset
 
 import (
 	"fmt"
+	"hash/fnv"
+	"math/big"
 	"sort"
 	"strings"
 
@@ -16,8 +18,18 @@ import (
 
 // EndpointsTrafficSet captures a set of traffic attributes for tuples of (source IP range, desination IP range, TransportSet),
 // where TransportSet is a set of TCP/UPD/ICMP with their properties (src,dst ports / icmp type,code)
+// Like a TransportSet, a set is strict if its transports are strict (see TransportSet.Strict), also when it is empty.
 type EndpointsTrafficSet struct {
-	props ds.TripleSet[*IPBlock, *IPBlock, *TransportSet]
+	props  ds.TripleSet[*IPBlock, *IPBlock, *TransportSet]
+	strict bool
+}
+
+// newTrafficSet returns the set of the given connections and strictness. The strictness of a BDD is held by the BDD.
+func newTrafficSet(props ds.TripleSet[*IPBlock, *IPBlock, *TransportSet], strict bool) *EndpointsTrafficSet {
+	if b, ok := props.(*bddTrafficSet); ok {
+		strict = b.strict
+	}
+	return &EndpointsTrafficSet{props: props, strict: strict}
 }
 
 // EmptyEndpointsTrafficSet returns an empty EndpointsTrafficSet
@@ -27,20 +39,41 @@ func EmptyEndpointsTrafficSet() *EndpointsTrafficSet {
 
 // Equal returns true is this EndpointsTrafficSet captures the exact same set of connections as `other` does.
 func (c *EndpointsTrafficSet) Equal(other *EndpointsTrafficSet) bool {
-	return c.props.Equal(other.props)
+	a, b := c.operands(other)
+	return a.Equal(b)
 }
 
 // Copy returns new EndpointsTrafficSet object with same set of connections as current one
 func (c *EndpointsTrafficSet) Copy() *EndpointsTrafficSet {
 	return &EndpointsTrafficSet{
-		props: c.props.Copy(),
+		props:  c.props.Copy(),
+		strict: c.strict,
 	}
 }
 
 // Intersect returns a EndpointsTrafficSet object with connection tuples that result from intersection of
 // this and `other` sets
 func (c *EndpointsTrafficSet) Intersect(other *EndpointsTrafficSet) *EndpointsTrafficSet {
-	return &EndpointsTrafficSet{props: c.props.Intersect(other.props)}
+	a, b := c.operands(other)
+	return newTrafficSet(a.Intersect(b), c.strict || other.strict)
+}
+
+// Compare returns -1 if c<other, 1 if c>other, 0 o.w.
+// Sets are ordered lexicographically by their sorted cubes (see Partitions).
+func (c *EndpointsTrafficSet) Compare(other *EndpointsTrafficSet) int {
+	return withBackend(c.props, CubesBackend, c.strict).Compare(withBackend(other.props, CubesBackend, other.strict))
+}
+
+// Hash returns the hash value of this EndpointsTrafficSet.
+// It hashes the strings of the set's sorted cubes, ignoring the strictness of their transports (see
+// TransportSet.Equal), so that it does not depend on the backend.
+func (c *EndpointsTrafficSet) Hash() int {
+	h := fnv.New32a()
+	for _, cube := range c.Partitions() {
+		cube.S3 = cube.S3.NonStrict()
+		_, _ = h.Write([]byte(cubeStr(cube)))
+	}
+	return int(h.Sum32())
 }
 
 // IsEmpty returns true of the EndpointsTrafficSet is empty
@@ -48,40 +81,113 @@ func (c *EndpointsTrafficSet) IsEmpty() bool {
 	return c.props.IsEmpty()
 }
 
+// Size returns the number of concrete connections in this EndpointsTrafficSet.
+// It overflows for large sets; see BigSize.
+func (c *EndpointsTrafficSet) Size() int {
+	return c.props.Size()
+}
+
+// BigSize returns the exact number of concrete connections in this EndpointsTrafficSet
+func (c *EndpointsTrafficSet) BigSize() *big.Int {
+	return c.props.BigSize()
+}
+
 // Union returns a EndpointsTrafficSet object with connection tuples that result from union of
 // this and `other` sets
 func (c *EndpointsTrafficSet) Union(other *EndpointsTrafficSet) *EndpointsTrafficSet {
+	a, b := c.operands(other)
+	strict := c.unionStrict(other)
 	if other.IsEmpty() {
-		return c.Copy()
+		return newTrafficSet(a.Copy(), strict)
 	}
 	if c.IsEmpty() {
-		return other.Copy()
+		return newTrafficSet(b.Copy(), strict)
 	}
-	return &EndpointsTrafficSet{
-		props: c.props.Union(other.props),
+	return newTrafficSet(a.Union(b), strict)
+}
+
+// unionStrict returns the strictness of the union of c and other. As for TransportSet.Union, the union of a strict set
+// and a non-strict set is strict, unless the non-strict set holds ICMP connections that are not valid by RFC.
+func (c *EndpointsTrafficSet) unionStrict(other *EndpointsTrafficSet) bool {
+	if c.strict == other.strict {
+		return c.strict
 	}
+	nonStrict := c
+	if c.strict {
+		nonStrict = other
+	}
+	return nonStrict.IsSubset(NewEndpointsTrafficSet(NewIPBlock(), NewIPBlock(), AllTransportsStrict()))
 }
 
 // Subtract returns a EndpointsTrafficSet object with connection tuples that result from subtraction of
 // `other` from this set
 func (c *EndpointsTrafficSet) Subtract(other *EndpointsTrafficSet) *EndpointsTrafficSet {
+	a, b := c.operands(other)
 	if other.IsEmpty() {
-		return c.Copy()
+		return newTrafficSet(a.Copy(), c.strict)
 	}
-	return &EndpointsTrafficSet{props: c.props.Subtract(other.props)}
+	return newTrafficSet(a.Subtract(b), c.strict)
+}
+
+// Universe returns the set of all connections between any IPv4 addresses.
+// If the transports of this set are strict (see TransportSet.Strict), so are those of its universe.
+func (c *EndpointsTrafficSet) Universe() *EndpointsTrafficSet {
+	if b, ok := c.props.(*bddTrafficSet); ok {
+		return newTrafficSet(b.m.universe(b.strict), c.strict)
+	}
+	return newTrafficSet(ds.TripleUniverse(NewIPBlock(), NewIPBlock(), c.noTransports()), c.strict)
+}
+
+// Complement returns the connections of the universe (see Universe) that are not in this set
+func (c *EndpointsTrafficSet) Complement() *EndpointsTrafficSet {
+	if c.Backend() == BDDBackend {
+		return c.Universe().Subtract(c)
+	}
+	return newTrafficSet(ds.TripleComplement(c.props, NewIPBlock(), NewIPBlock(), c.noTransports()), c.strict)
+}
+
+// IsAll returns true if this set holds all connections between any IPv4 addresses
+func (c *EndpointsTrafficSet) IsAll() bool {
+	if c.Backend() == BDDBackend {
+		return c.Equal(c.Universe())
+	}
+	return ds.TripleIsAll(c.props, NewIPBlock(), NewIPBlock(), c.noTransports())
+}
+
+// noTransports returns an empty TransportSet, which is strict if c is strict
+func (c *EndpointsTrafficSet) noTransports() *TransportSet {
+	if c.strict {
+		return NoTransports().Strict()
+	}
+	return NoTransports()
+}
+
+// strictTransports returns true if the transports of some cube are strict, and the transports of all the cubes hold
+// only ICMP connections that are valid by RFC. As for TransportSet.Union, these are the cubes of a strict universe.
+func strictTransports[S any](cubes []ds.Triple[S, S, *TransportSet]) bool {
+	strict := false
+	for _, cube := range cubes {
+		if !cube.S3.ICMPSet().IsSubset(validICMP) {
+			return false
+		}
+		strict = strict || cube.S3.IsStrict()
+	}
+	return strict
 }
 
 // IsSubset returns true if c is subset of other
 func (c *EndpointsTrafficSet) IsSubset(other *EndpointsTrafficSet) bool {
-	return c.props.IsSubset(other.props)
+	a, b := c.operands(other)
+	return a.IsSubset(b)
 }
 
 // NewEndpointsTrafficSet returns a new EndpointsTrafficSet object from input src, dst IP-ranges sets ands
 // TransportSet connections
 func NewEndpointsTrafficSet(src, dst *IPBlock, conn *TransportSet) *EndpointsTrafficSet {
-	return &EndpointsTrafficSet{props: ds.CartesianLeftTriple(src, dst, conn)}
+	return &EndpointsTrafficSet{props: ds.CartesianLeftTriple(src, dst, conn), strict: conn.IsStrict()}
 }
 
+// Partitions returns the cubes of this set, sorted by ds.CompareTriples
 func (c *EndpointsTrafficSet) Partitions() []ds.Triple[*IPBlock, *IPBlock, *TransportSet] {
 	return c.props.Partitions()
 }
@@ -99,3 +205,10 @@ func (c *EndpointsTrafficSet) String() string {
 	sort.Strings(resStrings)
 	return strings.Join(resStrings, comma)
 }
+
+// Compose returns the connections from sources of this set, through an intermediate endpoint, to destinations of
+// `other`: (src, dst, conn) such that for some IP x, (src, x, conn) is in this set and (x, dst, conn) is in `other`
+func (c *EndpointsTrafficSet) Compose(other *EndpointsTrafficSet) *EndpointsTrafficSet {
+	strict := c.strict || other.strict
+	return newTrafficSet(withBackend(ds.ComposeTriples(c.props, other.props), c.Backend(), strict), strict)
+}
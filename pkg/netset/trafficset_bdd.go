@@ -0,0 +1,325 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset
+
+import (
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/np-guard/models/pkg/bdd"
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/interval"
+)
+
+// TrafficSetBackend is the representation of an EndpointsTrafficSet
+type TrafficSetBackend int32
+
+const (
+	// CubesBackend represents a set as a union of cubes of IP addresses and transports (see Partitions)
+	CubesBackend TrafficSetBackend = iota
+	// BDDBackend represents a set as a binary decision diagram over the bits of the IP addresses, protocol and ports.
+	// It is faster than CubesBackend for sets with many partitions, but its Partitions are computed on demand.
+	BDDBackend
+)
+
+// The variables of the traffic sets' BDDs, by their order: the bits of the source IP address, the destination IP address,
+// the protocol, and the source and destination ports. The ports variables hold the type and code of ICMP connections.
+const (
+	srcIPVar    = 0
+	dstIPVar    = srcIPVar + maxIPv4Bits
+	protocolVar = dstIPVar + maxIPv4Bits
+	srcPortVar  = protocolVar + protocolBits
+	dstPortVar  = srcPortVar + portBits
+	numVars     = dstPortVar + portBits
+
+	protocolBits = 2
+	portBits     = 16
+
+	// icmpProtocolCode is the value of the protocol variables for ICMP connections (see TCPCode and UDPCode)
+	icmpProtocolCode = 2
+
+	// minManagerNodes is the number of nodes that a manager may hold before its results are moved to a new manager
+	minManagerNodes = 1 << 16
+	// managerGrowth is the factor by which a manager may grow before its results are moved to a new manager
+	managerGrowth = 4
+)
+
+// trafficManager holds the BDDs of a family of traffic sets: the result of an operation is held by the manager of
+// its first operand, so that related sets share their nodes. As a manager never frees nodes, once it holds more than
+// limit nodes the results of its operations are moved to new managers, and it is freed with the sets that use it.
+type trafficManager struct {
+	*bdd.Manager
+	limit int
+}
+
+func newTrafficManager() *trafficManager {
+	return &trafficManager{Manager: bdd.NewManager(numVars), limit: minManagerNodes}
+}
+
+// bddTrafficSet is a set of connections, represented by a node of a trafficManager.
+// Like a set of cubes whose transports are strict (see TransportSet.Strict), a strict set holds only ICMP connections
+// that are valid by RFC, and so does its universe; the transports of its cubes are strict.
+type bddTrafficSet struct {
+	m      *trafficManager
+	node   bdd.Node
+	strict bool
+	cubes  func() trafficTriples
+}
+
+func newBDDTrafficSet(m *trafficManager, node bdd.Node, strict bool) *bddTrafficSet {
+	res := &bddTrafficSet{m: m, node: node, strict: strict}
+	res.cubes = sync.OnceValue(res.buildCubes)
+	return res
+}
+
+type trafficTriples = ds.TripleSet[*IPBlock, *IPBlock, *TransportSet]
+
+func (m *trafficManager) setBDD(set *interval.CanonicalSet, first, width int) bdd.Node {
+	res := bdd.False
+	for _, span := range set.Intervals() {
+		//nolint:gosec // the values of the sets are within the range of the variables
+		res = m.Or(res, m.Range(first, width, uint64(span.Start()), uint64(span.End())))
+	}
+	return res
+}
+
+func (m *trafficManager) transportBDD(t *TransportSet) bdd.Node {
+	res := bdd.False
+	for _, cube := range t.TCPUDPSet().Partitions() {
+		ports := m.And(m.setBDD(cube.S2, srcPortVar, portBits), m.setBDD(cube.S3, dstPortVar, portBits))
+		res = m.Or(res, m.And(m.setBDD(cube.S1, protocolVar, protocolBits), ports))
+	}
+	for _, cube := range t.ICMPSet().Partitions() {
+		typesCodes := m.And(m.setBDD(cube.Left, srcPortVar, portBits), m.setBDD(cube.Right, dstPortVar, portBits))
+		protocol := m.Range(protocolVar, protocolBits, icmpProtocolCode, icmpProtocolCode)
+		res = m.Or(res, m.And(protocol, typesCodes))
+	}
+	return res
+}
+
+// node returns the node of m that represents a set of connections
+func (m *trafficManager) node(t trafficTriples) bdd.Node {
+	if b, ok := t.(*bddTrafficSet); ok {
+		return m.Import(b.m.Manager, b.node)
+	}
+	res := bdd.False
+	for _, cube := range t.Partitions() {
+		ips := m.And(m.setBDD(cube.S1.ipRange, srcIPVar, maxIPv4Bits), m.setBDD(cube.S2.ipRange, dstIPVar, maxIPv4Bits))
+		res = m.Or(res, m.And(ips, m.transportBDD(cube.S3)))
+	}
+	return res
+}
+
+// result returns the set of a node of m, moving it to a new manager if m holds too many nodes
+func (m *trafficManager) result(node bdd.Node, strict bool) *bddTrafficSet {
+	if m.NumNodes() <= m.limit {
+		return newBDDTrafficSet(m, node, strict)
+	}
+	fresh := newTrafficManager()
+	node = fresh.Import(m.Manager, node)
+	fresh.limit = max(minManagerNodes, managerGrowth*fresh.NumNodes())
+	return newBDDTrafficSet(fresh, node, strict)
+}
+
+// universeNode returns the node of all TCP, UDP and ICMP connections between any IP addresses, with only the ICMP
+// connections that are valid by RFC if strict is true
+func (m *trafficManager) universeNode(strict bool) bdd.Node {
+	if strict {
+		return m.transportBDD(AllTransportsStrict())
+	}
+	return m.transportBDD(AllTransports())
+}
+
+// universe returns the set of all connections of the universe of a set with the given strictness
+func (m *trafficManager) universe(strict bool) *bddTrafficSet {
+	return m.result(m.universeNode(strict), strict)
+}
+
+// convert returns the set of a node of m that represents t. If t is a set of cubes, the set has the given strictness.
+func (m *trafficManager) convert(t trafficTriples, strict bool) *bddTrafficSet {
+	if b, ok := t.(*bddTrafficSet); ok {
+		if b.m == m {
+			return b
+		}
+		return newBDDTrafficSet(m, m.node(t), b.strict)
+	}
+	return newBDDTrafficSet(m, m.node(t), strict)
+}
+
+// toBDD returns the BDD representation of a set of connections, held by a new manager if t is not a BDD
+func toBDD(t trafficTriples, strict bool) *bddTrafficSet {
+	if b, ok := t.(*bddTrafficSet); ok {
+		return b
+	}
+	return newTrafficManager().convert(t, strict)
+}
+
+// spanSets returns the sets of values of the variables [first, first+width) in the spans of a, by the spans' functions
+func (m *trafficManager) spanSets(a bdd.Node, first, width int) (nodes []bdd.Node, sets map[bdd.Node]*interval.CanonicalSet) {
+	sets = map[bdd.Node]*interval.CanonicalSet{}
+	for _, span := range m.Spans(a, first, width) {
+		if _, ok := sets[span.Node]; !ok {
+			nodes = append(nodes, span.Node)
+			sets[span.Node] = interval.NewCanonicalSet()
+		}
+		sets[span.Node].AddInterval(interval.New(int64(span.Lo), int64(span.Hi))) //nolint:gosec // values are at most 32 bits
+	}
+	return nodes, sets
+}
+
+// transportFromBDD returns the transports of a function of the protocol and ports variables, with the given strictness
+func (m *trafficManager) transportFromBDD(a bdd.Node, strict bool) *TransportSet {
+	tcpudp, icmp := EmptyTCPorUDPSet(), EmptyICMPSet()
+	for _, protocolSpan := range m.Spans(a, protocolVar, protocolBits) {
+		srcNodes, srcSets := m.spanSets(protocolSpan.Node, srcPortVar, portBits)
+		for _, srcNode := range srcNodes {
+			dstNodes, dstSets := m.spanSets(srcNode, dstPortVar, portBits)
+			for _, dstNode := range dstNodes {
+				for protocol := protocolSpan.Lo; protocol <= protocolSpan.Hi; protocol++ {
+					if protocol == icmpProtocolCode {
+						icmp = icmp.Union(icmpPropsPathLeft(srcSets[srcNode], dstSets[dstNode]))
+					} else {
+						protocolSet := interval.New(int64(protocol), int64(protocol)).ToSet() //nolint:gosec // protocol is a 2 bits value
+						tcpudp = tcpudp.Union(tcpudpPathLeft(protocolSet, srcSets[srcNode], dstSets[dstNode]))
+					}
+				}
+			}
+		}
+	}
+	return &TransportSet{set: ds.NewDisjoint(tcpudp, icmp), strict: strict}
+}
+
+// buildCubes returns the representation of the set as a union of cubes, which is cached by cubes.
+// The cubes are built directly in their canonical form, as the spans of the BDD are disjoint and have distinct functions.
+func (b *bddTrafficSet) buildCubes() trafficTriples {
+	var transportNodes []bdd.Node
+	ips := map[bdd.Node][]ds.Pair[*IPBlock, *IPBlock]{}
+	srcNodes, srcSets := b.m.spanSets(b.node, srcIPVar, maxIPv4Bits)
+	for _, srcNode := range srcNodes {
+		dstNodes, dstSets := b.m.spanSets(srcNode, dstIPVar, maxIPv4Bits)
+		for _, dstNode := range dstNodes {
+			if _, ok := ips[dstNode]; !ok {
+				transportNodes = append(transportNodes, dstNode)
+			}
+			ips[dstNode] = append(ips[dstNode], ds.Pair[*IPBlock, *IPBlock]{
+				Left: &IPBlock{ipRange: srcSets[srcNode]}, Right: &IPBlock{ipRange: dstSets[dstNode]}})
+		}
+	}
+	pairs := make([]ds.Pair[ds.Product[*IPBlock, *IPBlock], *TransportSet], len(transportNodes))
+	for i, node := range transportNodes {
+		pairs[i] = ds.Pair[ds.Product[*IPBlock, *IPBlock], *TransportSet]{
+			Left: ds.ProductLeftFromDisjoint(ips[node]...), Right: b.m.transportFromBDD(node, b.strict)}
+	}
+	return ds.LeftTripleSetFromDisjoint(pairs...)
+}
+
+func (b *bddTrafficSet) Equal(other trafficTriples) bool {
+	return b.node == b.m.node(other)
+}
+
+func (b *bddTrafficSet) Copy() trafficTriples {
+	return b
+}
+
+// Hash returns the hash value of the set's cubes, so that it does not depend on the backend
+func (b *bddTrafficSet) Hash() int {
+	return b.cubes().Hash()
+}
+
+func (b *bddTrafficSet) IsEmpty() bool {
+	return b.node == bdd.False
+}
+
+// Size returns the number of connections in the set, modulo 2^64
+func (b *bddTrafficSet) Size() int {
+	count := b.m.Count(b.node)
+	return int(count.And(count, new(big.Int).SetUint64(math.MaxUint64)).Uint64()) //nolint:gosec // the size wraps around like the cubes' size
+}
+
+func (b *bddTrafficSet) BigSize() *big.Int {
+	return b.m.Count(b.node)
+}
+
+// Compare compares the sets by their cubes, so that the order does not depend on the backend
+func (b *bddTrafficSet) Compare(other trafficTriples) int {
+	return b.cubes().Compare(withBackend(other, CubesBackend, false))
+}
+
+func (b *bddTrafficSet) IsSubset(other trafficTriples) bool {
+	return b.m.Diff(b.node, b.m.node(other)) == bdd.False
+}
+
+// Union returns the connections in b or in other. As for TransportSet.Union, the union of a strict set and a non-strict
+// set is strict, unless the non-strict set holds ICMP connections that are not valid by RFC.
+// The operands of the BDDs' operations are BDDs (see operands), which hold their strictness.
+func (b *bddTrafficSet) Union(other trafficTriples) trafficTriples {
+	o := b.m.convert(other, false)
+	strict := b.strict && o.strict
+	if b.strict != o.strict {
+		nonStrict := b
+		if b.strict {
+			nonStrict = o
+		}
+		strict = b.m.Diff(nonStrict.node, b.m.universeNode(true)) == bdd.False
+	}
+	return b.m.result(b.m.Or(b.node, o.node), strict)
+}
+
+func (b *bddTrafficSet) Intersect(other trafficTriples) trafficTriples {
+	o := b.m.convert(other, false)
+	return b.m.result(b.m.And(b.node, o.node), b.strict || o.strict)
+}
+
+func (b *bddTrafficSet) Subtract(other trafficTriples) trafficTriples {
+	return b.m.result(b.m.Diff(b.node, b.m.node(other)), b.strict)
+}
+
+func (b *bddTrafficSet) String() string {
+	return b.cubes().String()
+}
+
+func (b *bddTrafficSet) Partitions() []ds.Triple[*IPBlock, *IPBlock, *TransportSet] {
+	return b.cubes().Partitions()
+}
+
+// withBackend returns the representation of t in the given backend. A BDD of a set of cubes has the given strictness.
+func withBackend(t trafficTriples, backend TrafficSetBackend, strict bool) trafficTriples {
+	b, isBDD := t.(*bddTrafficSet)
+	switch {
+	case backend == BDDBackend && !isBDD:
+		return toBDD(t, strict)
+	case backend == CubesBackend && isBDD:
+		return b.cubes()
+	}
+	return t
+}
+
+// Backend returns the backend of the set
+func (c *EndpointsTrafficSet) Backend() TrafficSetBackend {
+	if _, ok := c.props.(*bddTrafficSet); ok {
+		return BDDBackend
+	}
+	return CubesBackend
+}
+
+// WithBackend returns the set represented in the given backend
+func (c *EndpointsTrafficSet) WithBackend(backend TrafficSetBackend) *EndpointsTrafficSet {
+	return newTrafficSet(withBackend(c.props, backend, c.strict), c.strict)
+}
+
+// operands returns the representations of c and other, both in BDDBackend if one of them is.
+// Both are held by the manager of c if it is a BDD, and otherwise by the manager of other.
+func (c *EndpointsTrafficSet) operands(other *EndpointsTrafficSet) (a, b trafficTriples) {
+	if x, ok := c.props.(*bddTrafficSet); ok {
+		return x, x.m.convert(other.props, other.strict)
+	}
+	if y, ok := other.props.(*bddTrafficSet); ok {
+		return y.m.convert(c.props, c.strict), y
+	}
+	return c.props, other.props
+}
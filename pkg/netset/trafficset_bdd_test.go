@@ -0,0 +1,98 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func TestBDDBackend(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for range 50 {
+		a, b := randomEndpointsTrafficSet(r), randomEndpointsTrafficSet(r)
+		bddA := a.WithBackend(netset.BDDBackend)
+		require.Equal(t, netset.BDDBackend, bddA.Backend())
+		require.Equal(t, netset.BDDBackend, bddA.Union(b).Backend())
+		require.Equal(t, netset.BDDBackend, b.Subtract(bddA).Backend())
+
+		require.True(t, bddA.Equal(a))
+		require.True(t, a.Equal(bddA))
+		require.Equal(t, a.Hash(), bddA.Hash())
+		require.Equal(t, a.Size(), bddA.Size())
+		require.Equal(t, 0, a.Compare(bddA))
+		require.Equal(t, a.String(), bddA.String())
+		require.Equal(t, a.Union(b).String(), bddA.Union(b).String())
+		require.Equal(t, a.Intersect(b).String(), bddA.Intersect(b).String())
+		require.Equal(t, a.Subtract(b).String(), bddA.Subtract(b).String())
+		require.Equal(t, a.Compare(b), bddA.Compare(b))
+		require.Equal(t, a.IsSubset(b), bddA.IsSubset(b))
+		require.True(t, a.Complement().Equal(bddA.Complement()))
+		require.True(t, bddA.WithBackend(netset.CubesBackend).Equal(a))
+		require.Equal(t, netset.CubesBackend, bddA.WithBackend(netset.CubesBackend).Backend())
+	}
+	require.True(t, netset.EmptyEndpointsTrafficSet().WithBackend(netset.BDDBackend).Complement().IsAll())
+}
+
+func TestBDDBackendIsExplicit(t *testing.T) {
+	src, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	https := netset.NewEndpointsTrafficSet(src, netset.GetCidrAll(), netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 443, 443))
+	ssh := netset.NewEndpointsTrafficSet(src, netset.GetCidrAll(), netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22))
+	require.Equal(t, netset.CubesBackend, https.Backend())
+	require.Equal(t, netset.CubesBackend, netset.EmptyEndpointsTrafficSet().Backend())
+
+	// sets that are converted separately are held by different managers
+	bddHTTPS, bddSSH := https.WithBackend(netset.BDDBackend), ssh.WithBackend(netset.BDDBackend)
+	require.Equal(t, "src: 10.240.10.0/24, dst: 0.0.0.0/0, conns: TCP dst-ports: 443", bddHTTPS.String())
+	require.True(t, bddHTTPS.Union(bddSSH).Equal(https.Union(ssh)))
+	require.True(t, bddSSH.Union(bddHTTPS).Subtract(bddHTTPS).Equal(ssh))
+	require.False(t, bddHTTPS.Equal(bddSSH))
+	require.Equal(t, https.Size(), ssh.Size())
+	require.NotEqual(t, bddHTTPS.Hash(), bddSSH.Hash())
+	require.Equal(t, ssh.Hash(), bddSSH.Hash())
+}
+
+func TestBDDBackendManyPartitions(t *testing.T) {
+	cubes := netset.EmptyEndpointsTrafficSet().WithBackend(netset.BDDBackend)
+	expectedSize := 0
+	for i := range 500 {
+		src, _ := netset.IPBlockFromIPAddress(fmt.Sprintf("10.%d.%d.1", i/256, i%256))
+		dst, _ := netset.IPBlockFromIPAddress(fmt.Sprintf("192.168.%d.%d", i%256, i/256))
+		cubes = cubes.Union(netset.NewEndpointsTrafficSet(src, dst, netset.NewTCPTransport(netp.MinPort, netp.MaxPort, int64(i+1), int64(i+1))))
+		expectedSize += netp.MaxPort
+	}
+	require.Equal(t, expectedSize, cubes.Size())
+	require.Len(t, cubes.Partitions(), 500)
+}
+
+func TestBDDBackendStrict(t *testing.T) {
+	src, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	strict := netset.NewEndpointsTrafficSet(src, netset.GetCidrAll(),
+		netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 443, 443).Union(netset.AllICMPTransportStrict()))
+	bddStrict := strict.WithBackend(netset.BDDBackend)
+	roundTrip := bddStrict.WithBackend(netset.CubesBackend)
+	require.True(t, roundTrip.Equal(strict))
+	require.Equal(t, strict.String(), roundTrip.String())
+	for _, cube := range roundTrip.Partitions() {
+		require.True(t, cube.S3.IsStrict())
+	}
+	require.True(t, strict.Universe().IsAll())
+	require.True(t, bddStrict.Universe().IsAll())
+	require.True(t, strict.Universe().Equal(bddStrict.Universe()))
+	require.True(t, strict.Complement().Equal(bddStrict.Complement()))
+	require.True(t, bddStrict.Union(bddStrict.Complement()).IsAll())
+
+	// a union with ICMP connections that are not valid by RFC is not strict, as with cubes
+	invalid := netset.NewEndpointsTrafficSet(src, src, netset.NewICMPTransport(100, 100, 0, 0))
+	require.Equal(t, strict.Union(invalid).Complement().Size(), bddStrict.Union(invalid).Complement().Size())
+}
@@ -9,10 +9,12 @@ package netset_test
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/np-guard/models/pkg/ds"
 	"github.com/np-guard/models/pkg/netp"
 	"github.com/np-guard/models/pkg/netset"
 )
@@ -160,3 +162,33 @@ func TestConnectionSetBasicOperations(t *testing.T) {
 
 	fmt.Println("done")
 }
+
+func TestConnectionSetCompose(t *testing.T) {
+	subnet, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	gateway, _ := netset.IPBlockFromIPAddress("10.240.0.1")
+	internet, _ := netset.IPBlockFromCidr("8.0.0.0/8")
+	tcp443 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 443, 443)
+
+	toGateway := netset.NewEndpointsTrafficSet(subnet, gateway, netset.AllTCPTransport())
+	fromGateway := netset.NewEndpointsTrafficSet(gateway, internet, tcp443).Union(
+		netset.NewEndpointsTrafficSet(gateway, internet, netset.AllICMPTransport()))
+
+	require.True(t, toGateway.Compose(fromGateway).Equal(netset.NewEndpointsTrafficSet(subnet, internet, tcp443)))
+	require.True(t, fromGateway.Compose(toGateway).IsEmpty())
+}
+
+func TestConnectionSetBigSize(t *testing.T) {
+	universe := netset.EmptyEndpointsTrafficSet().Universe()
+	expected := new(big.Int).Lsh(netset.AllTransports().BigSize(), 64)
+	require.Zero(t, expected.Cmp(universe.BigSize()))
+	require.Zero(t, expected.Cmp(universe.WithBackend(netset.BDDBackend).BigSize()))
+	require.Equal(t, big.NewRat(1, 1), ds.Fraction(universe))
+
+	subnet, _ := netset.IPBlockFromCidr("10.0.0.0/8")
+	tcp443 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 443, 443)
+	conns := netset.NewEndpointsTrafficSet(subnet, netset.GetCidrAll(), tcp443)
+	require.Zero(t, new(big.Int).Lsh(big.NewInt(netp.MaxPort), 24+32).Cmp(conns.BigSize()))
+	require.Equal(t, big.NewRat(1, 256), ds.Fraction(subnet))
+	require.Equal(t, new(big.Rat).Mul(ds.Fraction(subnet), ds.Fraction(tcp443)), ds.Fraction(conns))
+	require.Equal(t, "0.0000000298", ds.Fraction(conns).FloatString(10))
+}
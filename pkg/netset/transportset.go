@@ -8,6 +8,7 @@ package netset
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/np-guard/models/pkg/ds"
@@ -17,10 +18,25 @@ import (
 // TransportSet captures connection-sets for protocols from {TCP, UDP, ICMP}
 type TransportSet struct {
 	set *ds.Disjoint[*TCPUDPSet, *ICMPSet]
+
+	// strict sets hold only the ICMP type and code pairs that are valid by RFC, and so does their universe.
+	// Strictness affects only the universe of a set (and thus Complement and IsAll): sets with the same connections
+	// are Equal, have the same Hash and are subsets of each other, regardless of their strictness.
+	strict bool
+}
+
+// validICMP is the set of ICMP type and code pairs that are valid by RFC
+var validICMP = ICMPSetFromRFCICMPSet(AllICMPSetStrict())
+
+func newTransportSet(set *ds.Disjoint[*TCPUDPSet, *ICMPSet], strict bool) *TransportSet {
+	if strict {
+		set = ds.NewDisjoint(set.Left(), set.Right().Intersect(validICMP))
+	}
+	return &TransportSet{set: set, strict: strict}
 }
 
 func NewTCPorUDPTransport(protocol netp.ProtocolString, srcMinP, srcMaxP, dstMinP, dstMaxP int64) *TransportSet {
-	return &TransportSet{ds.NewDisjoint(
+	return &TransportSet{set: ds.NewDisjoint(
 		NewTCPorUDPSet(protocol, srcMinP, srcMaxP, dstMinP, dstMaxP),
 		EmptyICMPSet(),
 	)}
@@ -36,21 +52,21 @@ func NewUDPTransport(srcMinP, srcMaxP, dstMinP, dstMaxP int64) *TransportSet {
 }
 
 func NewICMPTransportFromICMPSet(icmpSet *ICMPSet) *TransportSet {
-	return &TransportSet{ds.NewDisjoint(
+	return &TransportSet{set: ds.NewDisjoint(
 		EmptyTCPorUDPSet(),
 		icmpSet.Copy(),
 	)}
 }
 
 func NewICMPTransport(minType, maxType, minCode, maxCode int64) *TransportSet {
-	return &TransportSet{ds.NewDisjoint(
+	return &TransportSet{set: ds.NewDisjoint(
 		EmptyTCPorUDPSet(),
 		NewICMPSet(minType, maxType, minCode, maxCode),
 	)}
 }
 
 func NewTCPUDPTransportFromTCPUDPSet(tcpudpSet *TCPUDPSet) *TransportSet {
-	return &TransportSet{ds.NewDisjoint(
+	return &TransportSet{set: ds.NewDisjoint(
 		tcpudpSet.Copy(),
 		EmptyICMPSet(),
 	)}
@@ -87,19 +103,54 @@ func AllOrNothingTransport(allTcpudp, allIcmp bool) *TransportSet {
 	} else {
 		icmp = EmptyICMPSet()
 	}
-	return &TransportSet{ds.NewDisjoint(tcpudp, icmp)}
+	return &TransportSet{set: ds.NewDisjoint(tcpudp, icmp)}
 }
 
 func AllTransports() *TransportSet {
 	return AllOrNothingTransport(true, true)
 }
 
+// NewICMPTransportStrict returns a strict set of the ICMP connections in s
+func NewICMPTransportStrict(s *RFCICMPSet) *TransportSet {
+	return newTransportSet(ds.NewDisjoint(EmptyTCPorUDPSet(), ICMPSetFromRFCICMPSet(s)), true)
+}
+
+// AllICMPTransportStrict returns a strict set of all the ICMP connections that are valid by RFC
+func AllICMPTransportStrict() *TransportSet {
+	return AllICMPTransport().Strict()
+}
+
+// AllTransportsStrict returns a strict set of all TCP and UDP connections, and all the ICMP connections that are valid by RFC
+func AllTransportsStrict() *TransportSet {
+	return AllTransports().Strict()
+}
+
+// Strict returns a strict set of the connections in t, ignoring ICMP connections that are not valid by RFC
+func (t *TransportSet) Strict() *TransportSet {
+	return newTransportSet(t.set, true)
+}
+
+// NonStrict returns a non-strict set of the connections in t, whose universe holds all ICMP type and code pairs
+func (t *TransportSet) NonStrict() *TransportSet {
+	return newTransportSet(t.set, false)
+}
+
+// IsStrict returns true if the set holds only ICMP connections that are valid by RFC, and its universe is restricted accordingly
+func (t *TransportSet) IsStrict() bool {
+	return t.strict
+}
+
+// RFCICMPSet returns the ICMP connections in t that are valid by RFC
+func (t *TransportSet) RFCICMPSet() *RFCICMPSet {
+	return RFCICMPSetFromICMPSet(t.ICMPSet())
+}
+
 func NoTransports() *TransportSet {
 	return AllOrNothingTransport(false, false)
 }
 
 func (t *TransportSet) SwapPorts() *TransportSet {
-	return &TransportSet{ds.NewDisjoint(t.TCPUDPSet().SwapPorts(), t.ICMPSet())}
+	return &TransportSet{set: ds.NewDisjoint(t.TCPUDPSet().SwapPorts(), t.ICMPSet()), strict: t.strict}
 }
 
 func (t *TransportSet) TCPUDPSet() *TCPUDPSet {
@@ -110,12 +161,18 @@ func (t *TransportSet) ICMPSet() *ICMPSet {
 	return t.set.Right()
 }
 
+// Equal returns true if t and other hold the same connections, regardless of their strictness
 func (t *TransportSet) Equal(other *TransportSet) bool {
 	return t.set.Equal(other.set)
 }
 
+// Compare returns -1 if t<other, 1 if t>other, 0 o.w., comparing the TCP/UDP connections first
+func (t *TransportSet) Compare(other *TransportSet) int {
+	return t.set.Compare(other.set)
+}
+
 func (t *TransportSet) Copy() *TransportSet {
-	return &TransportSet{t.set.Copy()}
+	return &TransportSet{set: t.set.Copy(), strict: t.strict}
 }
 
 func (t *TransportSet) Hash() int {
@@ -127,28 +184,59 @@ func (t *TransportSet) IsEmpty() bool {
 }
 
 func (t *TransportSet) IsAll() bool {
-	return t.Equal(AllTransports())
+	return t.Equal(t.Universe())
+}
+
+// Universe returns the set of all TCP, UDP and ICMP connections (only the valid ICMP connections, for strict sets)
+func (t *TransportSet) Universe() *TransportSet {
+	if t.strict {
+		return AllTransportsStrict()
+	}
+	return AllTransports()
+}
+
+// Complement returns the TCP, UDP and ICMP connections not in this set
+func (t *TransportSet) Complement() *TransportSet {
+	return newTransportSet(ds.DisjointComplement(t.set), t.strict)
 }
 
 func (t *TransportSet) Size() int {
 	return t.set.Size()
 }
 
+// BigSize returns the exact number of TCP, UDP and ICMP connections in the set
+func (t *TransportSet) BigSize() *big.Int {
+	return t.set.BigSize()
+}
+
 // IsSubset returns true if c is subset of other
 func (t *TransportSet) IsSubset(other *TransportSet) bool {
 	return t.set.IsSubset(other.set)
 }
 
+// Union returns the connections in t or in other. The union of a strict set and a non-strict set is strict, unless
+// the non-strict set holds ICMP connections that are not valid by RFC: these do not belong to the strict universe,
+// so the union is then non-strict.
 func (t *TransportSet) Union(other *TransportSet) *TransportSet {
-	return &TransportSet{t.set.Union(other.set)}
+	strict := t.strict && other.strict
+	if t.strict != other.strict {
+		nonStrict := t
+		if t.strict {
+			nonStrict = other
+		}
+		strict = nonStrict.ICMPSet().IsSubset(validICMP)
+	}
+	return newTransportSet(t.set.Union(other.set), strict)
 }
 
+// Intersect returns the connections in both t and other. The intersection with a strict set is strict.
 func (t *TransportSet) Intersect(other *TransportSet) *TransportSet {
-	return &TransportSet{t.set.Intersect(other.set)}
+	return newTransportSet(t.set.Intersect(other.set), t.strict || other.strict)
 }
 
+// Subtract returns the connections in t that are not in other. The result is strict if t is strict.
 func (t *TransportSet) Subtract(other *TransportSet) *TransportSet {
-	return &TransportSet{t.set.Subtract(other.set)}
+	return newTransportSet(t.set.Subtract(other.set), t.strict)
 }
 
 func (t *TransportSet) Overlap(other *TransportSet) bool {
@@ -163,6 +251,9 @@ func (t *TransportSet) String() string {
 	}
 	tcpString := t.TCPUDPSet().String()
 	icmpString := t.ICMPSet().String()
+	if t.strict {
+		icmpString = t.RFCICMPSet().String()
+	}
 
 	// Special case: ICMP,UDP or ICMP,TCP
 	if strings.HasSuffix(tcpString, string(netp.ProtocolStringTCP)) || strings.HasSuffix(tcpString, string(netp.ProtocolStringUDP)) {
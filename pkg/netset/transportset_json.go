@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package netset
 
 import (
+	"fmt"
+
 	"github.com/np-guard/models/pkg/interval"
 	"github.com/np-guard/models/pkg/netp"
 	"github.com/np-guard/models/pkg/spec"
@@ -86,7 +88,21 @@ func getCubeAsICMPItems(typesSet, codesSet *interval.CanonicalSet) []spec.Icmp {
 	}
 }
 
+// getRFCICMPItems returns the items of the ICMP connections in s, with a single item for each type whose codes are all in s
+func getRFCICMPItems(s *RFCICMPSet) []spec.Icmp {
+	res := []spec.Icmp{}
+	for _, icmp := range s.Partitions() {
+		tc := icmp.ICMPTypeCode()
+		if tc == nil {
+			return []spec.Icmp{{Protocol: spec.IcmpProtocolICMP}}
+		}
+		res = append(res, spec.Icmp{Protocol: spec.IcmpProtocolICMP, Type: &tc.Type, Code: tc.Code})
+	}
+	return res
+}
+
 // ToJSON returns a `Details` object for JSON representation of the input connection Set.
+// For strict sets, only the ICMP connections that are valid by RFC are listed.
 func ToJSON(c *TransportSet) Details {
 	if c == nil {
 		return Details{}
@@ -105,6 +121,12 @@ func ToJSON(c *TransportSet) Details {
 			}
 		}
 	}
+	if c.IsStrict() {
+		for _, item := range getRFCICMPItems(c.RFCICMPSet()) {
+			res = append(res, item)
+		}
+		return Details(res)
+	}
 	for _, item := range c.ICMPSet().Partitions() {
 		icmpItems := getCubeAsICMPItems(item.Left, item.Right)
 		for _, item := range icmpItems {
@@ -113,3 +135,89 @@ func ToJSON(c *TransportSet) Details {
 	}
 	return Details(res)
 }
+
+// FromJSON returns the set of transports allowed by a list of protocols, e.g., as returned by ToJSON.
+// Omitted ports of TCP and UDP entries, and omitted ICMP types and codes, allow all values.
+// It returns an error if a port, type or code is out of its range, or if a minimal port is greater than the maximal one.
+func FromJSON(protocols spec.ProtocolList) (*TransportSet, error) {
+	res := NoTransports()
+	for i, item := range protocols {
+		protocol, err := spec.ParseProtocol(item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		var transports *TransportSet
+		switch p := protocol.(type) {
+		case spec.TcpUdp:
+			transports, err = tcpudpFromJSON(p)
+		case spec.Icmp:
+			transports, err = icmpFromJSON(p)
+		case spec.AnyProtocol:
+			transports = AllTransports()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		res = res.Union(transports)
+	}
+	return res, nil
+}
+
+// portOrDefault returns the port of a TcpUdp entry, or the default if it is omitted (zero)
+func portOrDefault(port, defaultPort int) int {
+	if port == 0 {
+		return defaultPort
+	}
+	return port
+}
+
+// checkRange returns an error if the value of a field is not in the range [minValue-maxValue]
+func checkRange(field string, value, minValue, maxValue int) error {
+	if value < minValue || value > maxValue {
+		return fmt.Errorf("%s %d is not in the range [%d-%d]", field, value, minValue, maxValue)
+	}
+	return nil
+}
+
+func checkPorts(direction string, minPort, maxPort int) error {
+	minField, maxField := "min_"+direction+"_port", "max_"+direction+"_port"
+	if err := checkRange(minField, minPort, netp.MinPort, netp.MaxPort); err != nil {
+		return err
+	}
+	if err := checkRange(maxField, maxPort, netp.MinPort, netp.MaxPort); err != nil {
+		return err
+	}
+	if minPort > maxPort {
+		return fmt.Errorf("%s %d is greater than %s %d", minField, minPort, maxField, maxPort)
+	}
+	return nil
+}
+
+func tcpudpFromJSON(p spec.TcpUdp) (*TransportSet, error) {
+	minSrc, maxSrc := portOrDefault(p.MinSourcePort, netp.MinPort), portOrDefault(p.MaxSourcePort, netp.MaxPort)
+	minDst, maxDst := portOrDefault(p.MinDestinationPort, netp.MinPort), portOrDefault(p.MaxDestinationPort, netp.MaxPort)
+	if err := checkPorts("source", minSrc, maxSrc); err != nil {
+		return nil, err
+	}
+	if err := checkPorts("destination", minDst, maxDst); err != nil {
+		return nil, err
+	}
+	return NewTCPorUDPTransport(netp.ProtocolString(p.Protocol), int64(minSrc), int64(maxSrc), int64(minDst), int64(maxDst)), nil
+}
+
+func icmpFromJSON(icmp spec.Icmp) (*TransportSet, error) {
+	types, codes := AllICMPTypes(), AllICMPCodes()
+	if icmp.Type != nil {
+		if err := checkRange("type", *icmp.Type, netp.MinICMPType, netp.MaxICMPType); err != nil {
+			return nil, err
+		}
+		types = interval.New(int64(*icmp.Type), int64(*icmp.Type)).ToSet()
+	}
+	if icmp.Code != nil {
+		if err := checkRange("code", *icmp.Code, netp.MinICMPCode, netp.MaxICMPCode); err != nil {
+			return nil, err
+		}
+		codes = interval.New(int64(*icmp.Code), int64(*icmp.Code)).ToSet()
+	}
+	return NewICMPTransportFromICMPSet(icmpPropsPathLeft(types, codes)), nil
+}
@@ -52,8 +52,8 @@ func TestNoConnectionsTransportSet(t *testing.T) {
 
 func TestBasicSetICMPTransportSet(t *testing.T) {
 	c := netset.NewICMPTransport(ICMPValue, ICMPValue, 5, 5)
-	fmt.Println(c) // "ICMP type: 3 code: 5"
-	require.Equal(t, "ICMP type: 3 code: 5", c.String())
+	fmt.Println(c) // "ICMP dest-unreachable/source-route-failed"
+	require.Equal(t, "ICMP dest-unreachable/source-route-failed", c.String())
 }
 
 func TestBasicSetTCPTransportSet(t *testing.T) {
@@ -82,7 +82,7 @@ func TestBasicSet2TransportSet(t *testing.T) {
 	except2 := netset.NewTCPorUDPTransport(netp.ProtocolStringTCP, 1, 65535, 1, 65535)
 
 	d := netset.AllTransports().Subtract(except1).Subtract(except2)
-	fmt.Println(d) // ICMP type: 0-2,4-254 | ICMP type: 3 code: 0-4,6-255;UDP
+	fmt.Println(d) // ICMP 0-2,dest-unreachable/0-4,dest-unreachable/6-255,4-254;UDP
 
 	require.Equal(t, 2, len(d.ICMPSet().Partitions()))
 	require.Equal(t, 1, len(d.TCPUDPSet().Partitions()))
@@ -105,3 +105,101 @@ func TestBasicSet3TransportSet(t *testing.T) {
 	d := netset.AllTransports().Subtract(c).Union(netset.NewICMPTransport(ICMPValue, ICMPValue, 5, 5))
 	require.Equal(t, netset.AllConnections, d.String())
 }
+
+func TestTransportSetDeterministicOrder(t *testing.T) {
+	var conns *netset.TransportSet
+	for i := 0; i < 20; i++ {
+		c := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, int64(100*i+1), int64(100*i+10)).Union(
+			netset.NewUDPTransport(netp.MinPort, netp.MaxPort, int64(100*i+1), int64(100*i+5)))
+		if conns == nil {
+			conns = c
+		} else {
+			conns = c.Union(conns)
+		}
+	}
+	partitions := conns.TCPUDPSet().Partitions()
+	require.Len(t, partitions, 2)
+	// TCP (protocol code 0) precedes UDP (protocol code 1)
+	require.Equal(t, "0", partitions[0].S1.String())
+	require.Equal(t, "1", partitions[1].S1.String())
+
+	expected := fmt.Sprint(netset.ToJSON(conns))
+	for i := 0; i < 10; i++ {
+		require.Equal(t, expected, fmt.Sprint(netset.ToJSON(conns.Copy())))
+	}
+	require.Equal(t, 0, conns.Compare(conns.Copy()))
+	require.Equal(t, -1, netset.NoTransports().Compare(conns))
+}
+
+func TestStrictTransportSet(t *testing.T) {
+	all := netset.AllTransportsStrict()
+	require.True(t, all.IsStrict())
+	require.True(t, all.IsAll())
+	require.False(t, netset.AllTransports().IsStrict())
+	require.False(t, netset.AllTransports().Equal(all))
+	require.Equal(t, netset.AllConnections, all.String())
+	require.True(t, all.Complement().IsEmpty())
+
+	allICMP := netset.AllICMPTransportStrict()
+	require.Equal(t, netset.AllICMPSetStrict().Size(), allICMP.Size())
+	require.Equal(t, "ICMP", allICMP.String())
+	require.Equal(t, "ICMP,TCP", allICMP.Union(netset.AllTCPTransport()).String())
+	require.True(t, netset.AllICMPTransport().Strict().Equal(allICMP))
+
+	icmp, err := netset.ParseICMPSetStrict("echo-request,dest-unreachable/port-unreachable")
+	require.Nil(t, err)
+	conns := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22).Union(netset.NewICMPTransportStrict(icmp))
+	require.True(t, conns.IsStrict())
+	require.Equal(t, "ICMP dest-unreachable/port-unreachable,echo-request;TCP dst-ports: 22", conns.String())
+	require.Equal(t, 1+2, len(netset.ToJSON(conns)))
+	require.True(t, conns.RFCICMPSet().Equal(icmp))
+	require.Equal(t, netset.AllICMPSetStrict().Size()-2, conns.Complement().RFCICMPSet().Size())
+
+	// strictness affects only the universe of a set
+	strictICMP := netset.NewICMPTransportStrict(icmp)
+	nonStrictICMP := strictICMP.NonStrict()
+	require.False(t, nonStrictICMP.IsStrict())
+	require.False(t, netset.AllICMPTransport().Strict().NonStrict().IsAll())
+	require.False(t, nonStrictICMP.Complement().Equal(strictICMP.Complement()))
+
+	// mixed operations keep the strict universe, unless they hold ICMP connections that are not valid by RFC
+	require.True(t, netset.AllTransports().Subtract(strictICMP).Complement().Equal(nonStrictICMP))
+	require.True(t, nonStrictICMP.Union(allICMP).Equal(allICMP))
+	require.True(t, nonStrictICMP.Union(allICMP).IsStrict())
+	require.True(t, netset.AllTransports().Intersect(strictICMP).Equal(strictICMP))
+	require.True(t, allICMP.Subtract(netset.NewICMPTransport(0, 0, 0, 0)).IsStrict())
+	invalid := netset.NewICMPTransport(100, 200, 0, 0)
+	require.False(t, allICMP.Union(invalid).IsStrict())
+	require.True(t, allICMP.Union(invalid).Equal(allICMP.NonStrict().Union(invalid)))
+	require.True(t, allICMP.Union(invalid).IsSubset(netset.AllICMPTransport()))
+}
+
+func TestMixedStrictness(t *testing.T) {
+	icmp, err := netset.ParseICMPSetStrict("echo-request,dest-unreachable/port-unreachable")
+	require.Nil(t, err)
+	for _, s := range []*netset.TransportSet{
+		netset.NoTransports(),
+		netset.AllTCPTransport(),
+		netset.NewICMPTransportStrict(icmp).NonStrict(),
+		netset.NewUDPTransport(53, 53, 1, 100).Union(netset.NewICMPTransportStrict(icmp)),
+	} {
+		strict, nonStrict := s.Strict(), s.NonStrict()
+		require.True(t, strict.Equal(nonStrict), s.String())
+		require.True(t, nonStrict.Equal(strict), s.String())
+		require.True(t, strict.IsSubset(nonStrict) && nonStrict.IsSubset(strict), s.String())
+		require.Equal(t, strict.Hash(), nonStrict.Hash(), s.String())
+		require.Equal(t, 0, strict.Compare(nonStrict), s.String())
+		require.True(t, strict.Union(nonStrict).IsStrict(), s.String())
+	}
+}
+
+func TestICMPSetConversions(t *testing.T) {
+	require.True(t, netset.RFCICMPSetFromICMPSet(netset.AllICMPSet()).IsAll())
+	require.Equal(t, netset.AllICMPSetStrict().Size(), netset.ICMPSetFromRFCICMPSet(netset.AllICMPSetStrict()).Size())
+	require.True(t, netset.RFCICMPSetFromICMPSet(netset.NewICMPSet(100, 200, 0, 255)).IsEmpty())
+
+	icmp, err := netset.ParseICMPSetStrict("echo-request,dest-unreachable,redirect/host-redirect")
+	require.Nil(t, err)
+	require.True(t, netset.RFCICMPSetFromICMPSet(netset.ICMPSetFromRFCICMPSet(icmp)).Equal(icmp))
+	require.Equal(t, "ICMP dest-unreachable/0-5,redirect/host-redirect,echo-request/0", netset.ICMPSetFromRFCICMPSet(icmp).String())
+}
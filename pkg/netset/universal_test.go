@@ -0,0 +1,85 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package netset_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/ds"
+	"github.com/np-guard/models/pkg/interval"
+	"github.com/np-guard/models/pkg/netp"
+	"github.com/np-guard/models/pkg/netset"
+)
+
+func checkComplement[S ds.Universal[S]](t *testing.T, s S) {
+	t.Helper()
+	complement := s.Complement()
+	require.True(t, complement.Intersect(s).IsEmpty())
+	require.True(t, complement.Union(s).IsAll())
+	require.True(t, complement.Union(s).Equal(s.Universe()))
+	require.True(t, complement.Complement().Equal(s))
+	require.False(t, s.IsAll())
+	require.True(t, s.Universe().IsAll())
+	require.True(t, s.Universe().Complement().IsEmpty())
+}
+
+func TestComplement(t *testing.T) {
+	cidr, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	tcp22 := netset.NewTCPTransport(netp.MinPort, netp.MaxPort, 22, 22)
+
+	checkComplement(t, cidr)
+	checkComplement(t, netset.NewTCPorUDPSet(netp.ProtocolStringUDP, 1, 100, 53, 53))
+	checkComplement(t, netset.NewICMPSet(3, 3, 0, 5))
+	checkComplement(t, netset.NewICMPSetStrict(netp.ICMP{TypeCode: &netp.ICMPTypeCode{Type: netp.Echo}}))
+	checkComplement(t, tcp22)
+	checkComplement(t, netset.NewEndpointsTrafficSet(cidr, cidr, tcp22))
+	checkComplement(t, netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 1).ToSet(), interval.New(2, 2).ToSet(), tcp22))
+
+	require.True(t, tcp22.Complement().ICMPSet().IsAll())
+	require.True(t, netset.NewEndpointsTrafficSet(netset.GetCidrAll(), netset.GetCidrAll(), netset.AllTransports()).IsAll())
+	require.True(t, netset.EmptyEndpointsTrafficSet().Complement().IsAll())
+	strict := netset.NewEndpointsTrafficSet(cidr, cidr, tcp22.Strict())
+	checkComplement(t, strict)
+	require.True(t, strict.Complement().Partitions()[0].S3.IsStrict())
+	require.True(t, netset.EmptyDiscreteEndpointsTrafficSet().Complement().IsAll())
+
+	endpoints := interval.New(0, 2).ToSet()
+	conns := netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 1).ToSet(), interval.New(2, 2).ToSet(), tcp22)
+	require.True(t, conns.ComplementIn(endpoints).Equal(conns.Complement().Intersect(
+		netset.NewDiscreteEndpointsTrafficSet(endpoints, endpoints, netset.AllTransports()))))
+	require.True(t, conns.ComplementIn(endpoints).Union(conns).Equal(
+		netset.NewDiscreteEndpointsTrafficSet(endpoints, endpoints, netset.AllTransports())))
+}
+
+func TestMixedStrictnessTrafficSets(t *testing.T) {
+	a, _ := netset.IPBlockFromCidr("10.240.10.0/24")
+	b, _ := netset.IPBlockFromCidr("10.240.20.0/24")
+	c, _ := netset.IPBlockFromCidr("10.240.30.0/24")
+	tcp := netset.AllTCPTransport()
+	mixed := netset.NewEndpointsTrafficSet(a, c, tcp).Union(netset.NewEndpointsTrafficSet(b, c, tcp.Strict()))
+	merged := netset.NewEndpointsTrafficSet(a.Union(b), c, tcp.Strict())
+	require.True(t, mixed.Equal(merged))
+	require.Equal(t, mixed.Hash(), merged.Hash())
+	require.Len(t, mixed.Partitions(), 1)
+
+	// an empty set keeps the strictness of its operands
+	for _, backend := range []netset.TrafficSetBackend{netset.CubesBackend, netset.BDDBackend} {
+		x := netset.NewEndpointsTrafficSet(a, c, tcp.Strict()).WithBackend(backend)
+		empty := x.Intersect(netset.NewEndpointsTrafficSet(b, c, tcp.Strict()))
+		require.True(t, empty.IsEmpty())
+		require.True(t, empty.Complement().Equal(x.Universe()))
+		require.False(t, empty.Complement().Equal(netset.EmptyEndpointsTrafficSet().Universe()))
+	}
+
+	strict := netset.NewDiscreteEndpointsTrafficSet(interval.New(0, 1).ToSet(), interval.New(2, 2).ToSet(),
+		netset.AllICMPTransportStrict())
+	checkComplement(t, strict)
+	require.True(t, strict.Union(strict.Complement()).IsAll())
+	require.True(t, strict.Universe().Partitions()[0].S3.IsStrict())
+}
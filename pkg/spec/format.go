@@ -0,0 +1,162 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package spec
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/np-guard/models/pkg/netp"
+)
+
+// this file is not generated: it defines the canonical formatting of specs, for code review and diff
+
+const formatIndent = 2
+
+// FormatJSON returns the canonical JSON representation of the spec (see canonical), indented by two spaces.
+// It returns the errors of Validate for invalid specs, whose values may not survive formatting, e.g., a port 0,
+// which is omitted and so becomes the default port.
+func (s *Spec) FormatJSON() ([]byte, error) {
+	c, err := s.canonical()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatYAML returns the canonical YAML representation of the spec (see canonical), indented by two spaces.
+// It has the same content as FormatJSON, with the keys of each mapping sorted.
+func (s *Spec) FormatYAML() ([]byte, error) {
+	data, err := s.FormatJSON()
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(formatIndent)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// canonical returns an equivalent spec in canonical form:
+//   - the items of segments and the nifs of instances are sorted, without duplicates
+//   - protocol entries are TcpUdp, Icmp and AnyProtocol values, sorted by protocol and without duplicates,
+//     and ports equal to their defaults are omitted
+//
+// The order of the required connections is kept. The keys of the maps are sorted when marshalled.
+func (s *Spec) canonical() (*Spec, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	res := &Spec{
+		Externals:           s.Externals,
+		Instances:           SpecInstances{},
+		Nifs:                s.Nifs,
+		RequiredConnections: make([]SpecRequiredConnectionsElem, len(s.RequiredConnections)),
+		Segments:            SpecSegments{},
+		Subnets:             s.Subnets,
+	}
+	for name, nifs := range s.Instances {
+		res.Instances[name] = SortedSet(nifs)
+	}
+	for name, segment := range s.Segments {
+		res.Segments[name] = Segment{Type: segment.Type, Items: SortedSet(segment.Items)}
+	}
+	for i, conn := range s.RequiredConnections {
+		protocols, err := canonicalProtocols(conn.AllowedProtocols)
+		if err != nil {
+			return nil, err
+		}
+		res.RequiredConnections[i] = conn
+		res.RequiredConnections[i].AllowedProtocols = protocols
+	}
+	return res, nil
+}
+
+// SortedSet returns a sorted copy of items, without duplicates
+func SortedSet(items []string) []string {
+	res := append([]string{}, items...)
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+func canonicalProtocols(protocols ProtocolList) (ProtocolList, error) {
+	type entry struct {
+		protocol string
+		data     string
+		value    any
+	}
+	entries := make([]entry, 0, len(protocols))
+	for _, item := range protocols {
+		protocol, err := ParseProtocol(item)
+		if err != nil {
+			return nil, err
+		}
+		var name string
+		switch p := protocol.(type) {
+		case TcpUdp:
+			p = canonicalPorts(p)
+			name, protocol = string(p.Protocol), p
+		case Icmp:
+			name = string(p.Protocol)
+		case AnyProtocol:
+			name = string(p.Protocol)
+		}
+		data, err := json.Marshal(protocol)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{name, string(data), protocol})
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return cmp.Or(cmp.Compare(a.protocol, b.protocol), cmp.Compare(a.data, b.data))
+	})
+	entries = slices.CompactFunc(entries, func(a, b entry) bool { return a.data == b.data })
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	res := make(ProtocolList, len(entries))
+	for i, e := range entries {
+		res[i] = e.value
+	}
+	return res, nil
+}
+
+// canonicalPorts omits the ports of p that are equal to their defaults
+func canonicalPorts(p TcpUdp) TcpUdp {
+	for _, port := range []*int{&p.MinSourcePort, &p.MinDestinationPort} {
+		if *port == netp.MinPort {
+			*port = 0
+		}
+	}
+	for _, port := range []*int{&p.MaxSourcePort, &p.MaxDestinationPort} {
+		if *port == netp.MaxPort {
+			*port = 0
+		}
+	}
+	return p
+}
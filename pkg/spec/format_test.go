@@ -0,0 +1,212 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package spec_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/np-guard/models/pkg/spec"
+)
+
+func jsonToYAML(t *testing.T, data string) string {
+	t.Helper()
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(data), &doc))
+	res, err := yaml.Marshal(doc)
+	require.NoError(t, err)
+	return string(res)
+}
+
+const unformattedSpec = `{
+	"required-connections": [
+		{
+			"src": {"type": "segment", "name": "all"},
+			"dst": {"type": "cidr", "name": "10.0.0.0/8"},
+			"allowed-protocols": [
+				{"protocol": "ICMP", "type": 8},
+				{"protocol": "UDP", "min_source_port": 1, "max_source_port": 65535, "min_destination_port": 53, "max_destination_port": 53},
+				{"protocol": "TCP", "min_destination_port": 443, "max_destination_port": 443},
+				{"protocol": "ANY"},
+				{"protocol": "UDP", "max_destination_port": 53, "min_destination_port": 53}
+			]
+		},
+		{"src": {"type": "subnet", "name": "sub2"}, "dst": {"type": "external", "name": "dns"}, "bidirectional": true}
+	],
+	"segments": {"all": {"items": ["sub2", "sub1", "sub2"], "type": "subnet"}},
+	"subnets": {"sub2": "10.240.20.0/24", "sub1": "10.240.10.0/24"},
+	"externals": {"dns": "8.8.8.8"}
+}`
+
+const formattedJSON = `{
+  "externals": {
+    "dns": "8.8.8.8"
+  },
+  "required-connections": [
+    {
+      "allowed-protocols": [
+        {
+          "protocol": "ANY"
+        },
+        {
+          "protocol": "ICMP",
+          "type": 8
+        },
+        {
+          "max_destination_port": 443,
+          "min_destination_port": 443,
+          "protocol": "TCP"
+        },
+        {
+          "max_destination_port": 53,
+          "min_destination_port": 53,
+          "protocol": "UDP"
+        }
+      ],
+      "dst": {
+        "name": "10.0.0.0/8",
+        "type": "cidr"
+      },
+      "src": {
+        "name": "all",
+        "type": "segment"
+      }
+    },
+    {
+      "bidirectional": true,
+      "dst": {
+        "name": "dns",
+        "type": "external"
+      },
+      "src": {
+        "name": "sub2",
+        "type": "subnet"
+      }
+    }
+  ],
+  "segments": {
+    "all": {
+      "items": [
+        "sub1",
+        "sub2"
+      ],
+      "type": "subnet"
+    }
+  },
+  "subnets": {
+    "sub1": "10.240.10.0/24",
+    "sub2": "10.240.20.0/24"
+  }
+}
+`
+
+const formattedYAML = `externals:
+  dns: 8.8.8.8
+required-connections:
+  - allowed-protocols:
+      - protocol: ANY
+      - protocol: ICMP
+        type: 8
+      - max_destination_port: 443
+        min_destination_port: 443
+        protocol: TCP
+      - max_destination_port: 53
+        min_destination_port: 53
+        protocol: UDP
+    dst:
+      name: 10.0.0.0/8
+      type: cidr
+    src:
+      name: all
+      type: segment
+  - bidirectional: true
+    dst:
+      name: dns
+      type: external
+    src:
+      name: sub2
+      type: subnet
+segments:
+  all:
+    items:
+      - sub1
+      - sub2
+    type: subnet
+subnets:
+  sub1: 10.240.10.0/24
+  sub2: 10.240.20.0/24
+`
+
+func TestFormat(t *testing.T) {
+	s, err := spec.ParseJSON([]byte(unformattedSpec))
+	require.NoError(t, err)
+
+	formatted, err := s.FormatJSON()
+	require.NoError(t, err)
+	require.Equal(t, formattedJSON, string(formatted))
+
+	formatted, err = s.FormatYAML()
+	require.NoError(t, err)
+	require.Equal(t, formattedYAML, string(formatted))
+
+	// formatting is idempotent, and YAML and JSON represent the same spec
+	fromJSON, err := spec.ParseJSON([]byte(formattedJSON))
+	require.NoError(t, err)
+	fromYAML, err := spec.ParseYAML([]byte(formattedYAML))
+	require.NoError(t, err)
+	for _, parsed := range []*spec.Spec{fromJSON, fromYAML} {
+		formatted, err = parsed.FormatJSON()
+		require.NoError(t, err)
+		require.Equal(t, formattedJSON, string(formatted))
+	}
+}
+
+func TestFormatGoValues(t *testing.T) {
+	code := 0
+	s := &spec.Spec{
+		Segments: spec.SpecSegments{"empty": {Type: spec.SegmentTypeCidr}},
+		RequiredConnections: []spec.SpecRequiredConnectionsElem{{
+			Src: spec.Resource{Name: "empty", Type: spec.ResourceTypeSegment},
+			Dst: spec.Resource{Name: "0.0.0.0/0", Type: spec.ResourceTypeCidr},
+			AllowedProtocols: spec.ProtocolList{
+				spec.Icmp{Protocol: spec.IcmpProtocolICMP, Code: &code},
+				spec.TcpUdp{Protocol: spec.TcpUdpProtocolTCP},
+				spec.Icmp{Protocol: spec.IcmpProtocolICMP, Code: &code},
+			},
+		}},
+	}
+	formatted, err := s.FormatYAML()
+	require.NoError(t, err)
+	require.Equal(t, `required-connections:
+  - allowed-protocols:
+      - code: 0
+        protocol: ICMP
+      - protocol: TCP
+    dst:
+      name: 0.0.0.0/0
+      type: cidr
+    src:
+      name: empty
+      type: segment
+segments:
+  empty:
+    items: []
+    type: cidr
+`, string(formatted))
+
+	s.RequiredConnections[0].AllowedProtocols = spec.ProtocolList{spec.Resource{Name: "x"}}
+	_, err = s.FormatJSON()
+	require.ErrorContains(t, err, `unknown protocol ""`)
+
+	// an explicit port 0 would be omitted, and so become the default port
+	s.RequiredConnections[0].AllowedProtocols = spec.ProtocolList{map[string]any{"protocol": "TCP", "min_source_port": 0}}
+	_, err = s.FormatYAML()
+	require.ErrorContains(t, err, "port 0 is not in the range [1-65535]")
+}
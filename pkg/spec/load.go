@@ -0,0 +1,41 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package spec
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// this file is not generated: it defines the loading of specs from JSON and YAML
+
+// ParseJSON parses and validates a spec in JSON
+func ParseJSON(data []byte) (*Spec, error) {
+	res := &Spec{}
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ParseYAML parses and validates a spec in YAML.
+// The YAML document is converted to JSON and parsed by ParseJSON, so both formats report the same errors.
+func ParseYAML(data []byte) (*Spec, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSON(data)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package spec_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/spec"
+)
+
+const yamlSpec = `
+subnets:
+  sub1: 10.240.10.0/24
+  sub2: 10.240.20.0/24
+segments:
+  all:
+    type: subnet
+    items: [sub2, sub1]
+required-connections:
+  - src: {name: all, type: segment}
+    dst: {name: 10.0.0.0/8, type: cidr}
+    allowed-protocols:
+      - protocol: TCP
+        min_destination_port: 443
+        max_destination_port: 443
+      - protocol: ICMP
+        type: 8
+`
+
+func TestParseYAML(t *testing.T) {
+	s, err := spec.ParseYAML([]byte(yamlSpec))
+	require.NoError(t, err)
+	require.Equal(t, spec.SpecSubnets{"sub1": "10.240.10.0/24", "sub2": "10.240.20.0/24"}, s.Subnets)
+	require.Equal(t, []string{"sub2", "sub1"}, s.Segments["all"].Items)
+	require.Len(t, s.RequiredConnections, 1)
+	require.Equal(t, spec.Resource{Name: "10.0.0.0/8", Type: spec.ResourceTypeCidr}, s.RequiredConnections[0].Dst)
+	require.Len(t, s.RequiredConnections[0].AllowedProtocols, 2)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		json      string
+		yaml      string
+		errString string
+	}{
+		{
+			name:      "missing field",
+			json:      `{"subnets": {"sub1": "10.240.10.0/24"}}`,
+			yaml:      "subnets:\n  sub1: 10.240.10.0/24\n",
+			errString: "field required-connections in Spec: required",
+		},
+		{
+			name:      "invalid enum",
+			json:      `{"segments": {"s": {"type": "vpc", "items": []}}, "required-connections": []}`,
+			yaml:      "segments:\n  s: {type: vpc, items: []}\nrequired-connections: []\n",
+			errString: `invalid value (expected one of []interface {}{"subnet", "cidr", "instance", "nif", "vpe"}): "vpc"`,
+		},
+		{
+			name:      "invalid semantics",
+			json:      invalidSpec,
+			yaml:      jsonToYAML(t, invalidSpec),
+			errString: `$.segments["web"].items[2]: subnet "sub9" is not defined`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, jsonErr := spec.ParseJSON([]byte(test.json))
+			require.ErrorContains(t, jsonErr, test.errString)
+			_, yamlErr := spec.ParseYAML([]byte(test.yaml))
+			require.Equal(t, jsonErr.Error(), yamlErr.Error())
+		})
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/np-guard/models/pkg/netp"
+)
+
+// this file is not generated: it defines the semantic validation of specs, beyond their JSON schema
+
+// ValidationError is a problem found by Validate
+type ValidationError struct {
+	// Path is the JSON path of the problematic value, e.g., `$.segments["web"].items[1]`
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors are all the problems found by Validate
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	res := make([]string, len(e))
+	for i, err := range e {
+		res[i] = err.Error()
+	}
+	return strings.Join(res, "\n")
+}
+
+// Validate checks the semantics of the spec, and returns all the problems it finds as ValidationErrors, or nil.
+// It checks that:
+//   - externals, subnets and nifs hold valid IPv4 CIDRs and addresses, and subnets do not overlap
+//   - names are unique across externals, instances, nifs, segments and subnets
+//   - segment items and the src and dst of required connections name resources of their declared type
+//   - TCP and UDP port ranges are within [1-65535] and not reversed, and ICMP types and codes are valid by RFC 792
+//
+// References to subnets, nifs and instances are checked only if the spec defines resources of that type,
+// as they may be defined elsewhere (e.g., in the configuration of a VPC).
+func (s *Spec) Validate() error {
+	v := &validator{spec: s, kinds: map[string]ResourceType{}}
+	v.validateNames()
+	v.validateAddresses()
+	v.validateSegments()
+	v.validateConnections()
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+type jsonPath string
+
+const rootPath jsonPath = "$"
+
+// the JSON names of the fields of Spec defining named resources
+const (
+	externalsField = "externals"
+	instancesField = "instances"
+	nifsField      = "nifs"
+	segmentsField  = "segments"
+	subnetsField   = "subnets"
+)
+
+// nameField is the JSON name of Resource.Name
+const nameField = "name"
+
+const invalidCIDRFormat = "invalid CIDR: %v"
+
+func (p jsonPath) field(name string) jsonPath {
+	return p + "." + jsonPath(name)
+}
+
+func (p jsonPath) key(name string) jsonPath {
+	return p + "[" + jsonPath(strconv.Quote(name)) + "]"
+}
+
+func (p jsonPath) index(i int) jsonPath {
+	return p + "[" + jsonPath(strconv.Itoa(i)) + "]"
+}
+
+type validator struct {
+	spec  *Spec
+	kinds map[string]ResourceType // the type of each named resource
+	errs  ValidationErrors
+}
+
+func (v *validator) report(path jsonPath, format string, a ...any) {
+	v.errs = append(v.errs, &ValidationError{Path: string(path), Message: fmt.Sprintf(format, a...)})
+}
+
+// SortedNames returns the keys of a map of named resources, sorted
+func SortedNames[V any](m map[string]V) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// namedResources are the names of the resources of a type, defined by a field of the spec
+type namedResources struct {
+	field string
+	kind  ResourceType
+	names []string
+}
+
+// namedResources returns the names of the resources of each type defined by the spec, in the order of their fields
+func (v *validator) namedResources() []namedResources {
+	return []namedResources{
+		{externalsField, ResourceTypeExternal, SortedNames(v.spec.Externals)},
+		{instancesField, ResourceTypeInstance, SortedNames(v.spec.Instances)},
+		{nifsField, ResourceTypeNif, SortedNames(v.spec.Nifs)},
+		{segmentsField, ResourceTypeSegment, SortedNames(v.spec.Segments)},
+		{subnetsField, ResourceTypeSubnet, SortedNames(v.spec.Subnets)},
+	}
+}
+
+func (v *validator) validateNames() {
+	for _, resources := range v.namedResources() {
+		for _, name := range resources.names {
+			if kind, ok := v.kinds[name]; ok {
+				v.report(rootPath.field(resources.field).key(name), "name %q is already defined as %s", name, kind)
+				continue
+			}
+			v.kinds[name] = resources.kind
+		}
+	}
+}
+
+func parseIPv4Prefix(s string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return prefix, err
+	}
+	if !prefix.Addr().Is4() {
+		return prefix, fmt.Errorf("%q is not an IPv4 CIDR", s)
+	}
+	return prefix, nil
+}
+
+func parseIPv4Addr(s string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return addr, err
+	}
+	if !addr.Is4() {
+		return addr, fmt.Errorf("%q is not an IPv4 address", s)
+	}
+	return addr, nil
+}
+
+// parseIPv4AddrOrPrefix parses a CIDR or a single address, as a prefix
+func parseIPv4AddrOrPrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return parseIPv4Prefix(s)
+	}
+	addr, err := parseIPv4Addr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func (v *validator) validateAddresses() {
+	for _, name := range SortedNames(v.spec.Externals) {
+		if _, err := parseIPv4AddrOrPrefix(v.spec.Externals[name]); err != nil {
+			v.report(rootPath.field(externalsField).key(name), "invalid CIDR or address: %v", err)
+		}
+	}
+	for _, name := range SortedNames(v.spec.Nifs) {
+		if _, err := parseIPv4Addr(v.spec.Nifs[name]); err != nil {
+			v.report(rootPath.field(nifsField).key(name), "invalid address: %v", err)
+		}
+	}
+	var subnets []string
+	prefixes := map[string]netip.Prefix{}
+	for _, name := range SortedNames(v.spec.Subnets) {
+		prefix, err := parseIPv4Prefix(v.spec.Subnets[name])
+		if err != nil {
+			v.report(rootPath.field(subnetsField).key(name), invalidCIDRFormat, err)
+			continue
+		}
+		for _, other := range subnets {
+			if prefixes[other].Overlaps(prefix) {
+				v.report(rootPath.field(subnetsField).key(name), "CIDR %s overlaps subnet %q (%s)", prefix, other, prefixes[other])
+			}
+		}
+		subnets = append(subnets, name)
+		prefixes[name] = prefix
+	}
+}
+
+// validateReference checks that name is a resource of the given type
+func (v *validator) validateReference(path jsonPath, kind ResourceType, name string) {
+	if kind == ResourceTypeCidr {
+		if _, err := parseIPv4AddrOrPrefix(name); err != nil {
+			v.report(path, invalidCIDRFormat, err)
+		}
+		return
+	}
+	actual, ok := v.kinds[name]
+	switch {
+	case ok && actual != kind:
+		v.report(path, "%q is %s, not %s", name, actual, kind)
+	case !ok && v.defines(kind):
+		v.report(path, "%s %q is not defined", kind, name)
+	}
+}
+
+// defines returns true if resources of the given type must be defined in the spec to be referenced
+func (v *validator) defines(kind ResourceType) bool {
+	switch kind {
+	case ResourceTypeExternal, ResourceTypeSegment:
+		return true
+	case ResourceTypeSubnet:
+		return len(v.spec.Subnets) > 0
+	case ResourceTypeNif:
+		return len(v.spec.Nifs) > 0
+	case ResourceTypeInstance:
+		return len(v.spec.Instances) > 0
+	}
+	return false
+}
+
+func (v *validator) validateSegments() {
+	for _, name := range SortedNames(v.spec.Segments) {
+		path := rootPath.field(segmentsField).key(name)
+		segment := v.spec.Segments[name]
+		for i, item := range segment.Items {
+			v.validateReference(path.field("items").index(i), ResourceType(segment.Type), item)
+		}
+	}
+}
+
+func (v *validator) validateConnections() {
+	for i, conn := range v.spec.RequiredConnections {
+		path := rootPath.field("required-connections").index(i)
+		v.validateReference(path.field("src").field(nameField), conn.Src.Type, conn.Src.Name)
+		v.validateReference(path.field("dst").field(nameField), conn.Dst.Type, conn.Dst.Name)
+		for j, protocol := range conn.AllowedProtocols {
+			v.validateProtocol(path.field("allowed-protocols").index(j), protocol)
+		}
+	}
+}
+
+// ParseProtocol returns an item of a ProtocolList as one of TcpUdp, Icmp and AnyProtocol.
+// The item is either one of them, or a map holding one of them, as unmarshalled from JSON.
+func ParseProtocol(item any) (any, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Protocol string `json:"protocol"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	switch header.Protocol {
+	case string(TcpUdpProtocolTCP), string(TcpUdpProtocolUDP):
+		var tcpudp TcpUdp
+		err = json.Unmarshal(data, &tcpudp)
+		return tcpudp, err
+	case string(IcmpProtocolICMP):
+		var icmp Icmp
+		err = json.Unmarshal(data, &icmp)
+		return icmp, err
+	case string(AnyProtocolProtocolANY):
+		var anyProtocol AnyProtocol
+		err = json.Unmarshal(data, &anyProtocol)
+		return anyProtocol, err
+	}
+	return nil, fmt.Errorf("unknown protocol %q", header.Protocol)
+}
+
+// validateProtocol checks an item of a ProtocolList (see ParseProtocol)
+func (v *validator) validateProtocol(path jsonPath, item any) {
+	protocol, err := ParseProtocol(item)
+	if err != nil {
+		v.report(path, "invalid protocol: %v", err)
+		return
+	}
+	switch p := protocol.(type) {
+	case TcpUdp:
+		v.validatePorts(path, "source", p.MinSourcePort, p.MaxSourcePort)
+		v.validatePorts(path, "destination", p.MinDestinationPort, p.MaxDestinationPort)
+	case Icmp:
+		v.validateICMP(path, p)
+	}
+}
+
+func (v *validator) validatePorts(path jsonPath, direction string, minPort, maxPort int) {
+	const portSuffix = "_port"
+	minField, maxField := "min_"+direction+portSuffix, "max_"+direction+portSuffix
+	for _, p := range []struct {
+		field string
+		port  int
+	}{{minField, minPort}, {maxField, maxPort}} {
+		if p.port < netp.MinPort || p.port > netp.MaxPort {
+			v.report(path.field(p.field), "port %d is not in the range [%d-%d]", p.port, netp.MinPort, netp.MaxPort)
+		}
+	}
+	if minPort > maxPort {
+		v.report(path, "%s %d is greater than %s %d", minField, minPort, maxField, maxPort)
+	}
+}
+
+// validateICMP checks the range of the ICMP code, if it is set, and the combination of the ICMP type and code,
+// if the type is set
+func (v *validator) validateICMP(path jsonPath, icmp Icmp) {
+	if icmp.Code != nil && (*icmp.Code < netp.MinICMPCode || *icmp.Code > netp.MaxICMPCode) {
+		v.report(path.field("code"), "code %d is not in the range [%d-%d]", *icmp.Code, netp.MinICMPCode, netp.MaxICMPCode)
+		return
+	}
+	if icmp.Type == nil {
+		return
+	}
+	if err := netp.ValidateICMP(&netp.ICMPTypeCode{Type: *icmp.Type, Code: icmp.Code}); err != nil {
+		v.report(path, "%v", err)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023- IBM Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package spec_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/np-guard/models/pkg/spec"
+)
+
+const invalidSpec = `{
+	"externals": {"dns": "8.8.8.8", "bad-external": "8.8.8.0/33", "sub1": "1.1.1.1"},
+	"subnets": {"sub1": "10.240.10.0/24", "sub2": "10.240.0.0/16", "sub3": "10.240.300.0/24"},
+	"nifs": {"nif1": "10.240.10.4"},
+	"segments": {
+		"web": {"type": "subnet", "items": ["sub1", "nif1", "sub9"]},
+		"hosts": {"type": "cidr", "items": ["10.0.0.0/8", "10.0.0.0.0"]}
+	},
+	"required-connections": [
+		{
+			"src": {"name": "web", "type": "segment"},
+			"dst": {"name": "dns", "type": "external"},
+			"allowed-protocols": [
+				{"protocol": "TCP", "min_destination_port": 53, "max_destination_port": 52},
+				{"protocol": "UDP", "min_source_port": 0},
+				{"protocol": "ICMP", "type": 3, "code": 6},
+				{"protocol": "ICMP", "type": 8, "code": 0},
+				{"protocol": "ICMP", "type": 8, "code": -1},
+				{"protocol": "ICMP", "code": 256},
+				{"protocol": "ANY"}
+			]
+		},
+		{"src": {"name": "db", "type": "segment"}, "dst": {"name": "nif1", "type": "subnet"}}
+	]
+}`
+
+func TestValidate(t *testing.T) {
+	var s spec.Spec
+	require.NoError(t, json.Unmarshal([]byte(invalidSpec), &s))
+	err := s.Validate()
+	var errs spec.ValidationErrors
+	require.True(t, errors.As(err, &errs))
+
+	messages := map[string]string{}
+	for _, e := range errs {
+		messages[e.Path] = e.Message
+	}
+	require.Equal(t, map[string]string{
+		`$.subnets["sub1"]`:           `name "sub1" is already defined as external`,
+		`$.externals["bad-external"]`: `invalid CIDR or address: netip.ParsePrefix("8.8.8.0/33"): prefix length out of range`,
+		`$.subnets["sub2"]`:           `CIDR 10.240.0.0/16 overlaps subnet "sub1" (10.240.10.0/24)`,
+		`$.subnets["sub3"]`: `invalid CIDR: netip.ParsePrefix("10.240.300.0/24"): ParseAddr("10.240.300.0"): ` +
+			`IPv4 field has value >255`,
+		`$.segments["web"].items[0]`:                                     `"sub1" is external, not subnet`,
+		`$.segments["web"].items[1]`:                                     `"nif1" is nif, not subnet`,
+		`$.segments["web"].items[2]`:                                     `subnet "sub9" is not defined`,
+		`$.segments["hosts"].items[1]`:                                   `invalid CIDR: ParseAddr("10.0.0.0.0"): IPv4 address too long`,
+		`$.required-connections[0].allowed-protocols[0]`:                 "min_destination_port 53 is greater than max_destination_port 52",
+		`$.required-connections[0].allowed-protocols[1].min_source_port`: "port 0 is not in the range [1-65535]",
+		`$.required-connections[0].allowed-protocols[2]`:                 "ICMP code 6 is invalid for ICMP type 3",
+		`$.required-connections[0].allowed-protocols[4].code`:            "code -1 is not in the range [0-255]",
+		`$.required-connections[0].allowed-protocols[5].code`:            "code 256 is not in the range [0-255]",
+		`$.required-connections[1].src.name`:                             `segment "db" is not defined`,
+		`$.required-connections[1].dst.name`:                             `"nif1" is nif, not subnet`,
+	}, messages)
+	require.Len(t, errs, len(messages))
+	require.Contains(t, err.Error(), `$.segments["web"].items[2]: subnet "sub9" is not defined`)
+}
+
+func TestValidateValidSpec(t *testing.T) {
+	code := 0
+	echo := 8
+	s := spec.Spec{
+		Subnets:  spec.SpecSubnets{"sub1": "10.240.10.0/24", "sub2": "10.240.20.0/24"},
+		Segments: spec.SpecSegments{"all": {Type: spec.SegmentTypeSubnet, Items: []string{"sub1", "sub2"}}},
+		RequiredConnections: []spec.SpecRequiredConnectionsElem{{
+			Src: spec.Resource{Name: "all", Type: spec.ResourceTypeSegment},
+			// instances are not defined in the spec, so they are not checked
+			Dst: spec.Resource{Name: "vsi1", Type: spec.ResourceTypeInstance},
+			AllowedProtocols: spec.ProtocolList{
+				spec.TcpUdp{Protocol: spec.TcpUdpProtocolTCP, MinDestinationPort: 443, MaxDestinationPort: 443},
+				spec.Icmp{Protocol: spec.IcmpProtocolICMP, Type: &echo, Code: &code},
+				spec.Icmp{Protocol: spec.IcmpProtocolICMP, Code: &code},
+				spec.AnyProtocol{Protocol: spec.AnyProtocolProtocolANY},
+			},
+		}},
+	}
+	require.NoError(t, s.Validate())
+}